@@ -0,0 +1,185 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+// AliasSwap describes a blue/green deployment of an index behind an alias
+type AliasSwap struct {
+	// Alias to move to the new index
+	Alias string
+
+	// NewIndex is created with Mapping, it must not exist yet
+	NewIndex string
+	Mapping  interface{}
+
+	// Load fills the new index, e.g. with BulkSend. When nil, the documents
+	// of the indexes currently behind the alias are reindexed into it.
+	Load func(c *Client, index string) error
+
+	// Verify checks the number of documents of the new index against the
+	// number of documents behind the alias. When nil, they must be equal.
+	Verify func(newCount int, oldCount int) error
+
+	// DeleteOld deletes the indexes previously behind the alias once it
+	// points to the new index
+	DeleteOld bool
+}
+
+// SwapAlias creates a new index, fills it and verifies its document count
+// before atomically moving the alias from its current indexes to the new one,
+// so that the alias never points to no index or to both. The new index is
+// deleted if it could not be filled or verified, leaving the alias untouched.
+func (c *Client) SwapAlias(swap AliasSwap) (*Response, error) {
+	oldIndexes, err := c.ResolveAlias(swap.Alias)
+	if searchErr, ok := err.(*SearchError); ok && searchErr.StatusCode == 404 {
+		// First deployment, the alias does not exist yet
+		oldIndexes, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.CreateIndex(swap.NewIndex, swap.Mapping); err != nil {
+		return nil, err
+	}
+
+	if err := c.fillSwapIndex(swap, oldIndexes); err != nil {
+		c.DeleteIndex(swap.NewIndex)
+		return nil, err
+	}
+
+	actions := make([]AliasAction, 0, len(oldIndexes)+1)
+	for _, index := range oldIndexes {
+		actions = append(actions, AliasAction{Action: AliasActionRemove, Index: index, Alias: swap.Alias})
+	}
+	actions = append(actions, AliasAction{Action: AliasActionAdd, Index: swap.NewIndex, Alias: swap.Alias})
+
+	resp, err := c.UpdateAliases(actions)
+	if err != nil {
+		c.DeleteIndex(swap.NewIndex)
+		return resp, err
+	}
+
+	if swap.DeleteOld {
+		for _, index := range oldIndexes {
+			if _, err := c.DeleteIndex(index); err != nil {
+				return resp, err
+			}
+		}
+	}
+
+	return resp, nil
+}
+
+func (c *Client) fillSwapIndex(swap AliasSwap, oldIndexes []string) error {
+	if swap.Load != nil {
+		if err := swap.Load(c, swap.NewIndex); err != nil {
+			return err
+		}
+	} else if len(oldIndexes) > 0 {
+		body := ReindexBody{
+			Source: ReindexSource{Index: oldIndexes},
+			Dest:   ReindexDest{Index: swap.NewIndex},
+		}
+		resp, err := c.Reindex(body, url.Values{})
+		if err != nil {
+			return err
+		}
+		// The new index is only partially filled, whatever Verify says
+		if len(resp.Failures) > 0 {
+			return fmt.Errorf("Reindexing into %s failed for %d documents: %s", swap.NewIndex, len(resp.Failures), resp.Failures[0])
+		}
+		if resp.TimedOut {
+			return fmt.Errorf("Reindexing into %s timed out", swap.NewIndex)
+		}
+	}
+
+	if _, err := c.RefreshIndex(swap.NewIndex); err != nil {
+		return err
+	}
+
+	newCount, err := c.Count(nil, []string{swap.NewIndex}, nil, url.Values{})
+	if err != nil {
+		return err
+	}
+
+	oldCount := &Response{}
+	if len(oldIndexes) > 0 {
+		if oldCount, err = c.Count(nil, oldIndexes, nil, url.Values{}); err != nil {
+			return err
+		}
+	}
+
+	if swap.Verify != nil {
+		return swap.Verify(newCount.Count, oldCount.Count)
+	}
+	if newCount.Count != oldCount.Count {
+		return fmt.Errorf("Index %s holds %d documents instead of %d", swap.NewIndex, newCount.Count, oldCount.Count)
+	}
+
+	return nil
+}
+
+// AliasDefinition holds the configuration of an alias on an index
+type AliasDefinition struct {
+	Filter        map[string]interface{} `json:"filter,omitempty"`
+	IndexRouting  string                 `json:"index_routing,omitempty"`
+	SearchRouting string                 `json:"search_routing,omitempty"`
+	IsWriteIndex  bool                   `json:"is_write_index,omitempty"`
+}
+
+// GetAliases returns the aliases of the indexes matching indexOrAlias, by
+// index then by alias name. indexOrAlias may be an index, an alias or a
+// wildcard expression, all the aliases are returned if it is empty.
+func (c *Client) GetAliases(indexOrAlias string) (map[string]map[string]AliasDefinition, error) {
+	r := Request{
+		Method: "GET",
+		API:    "_alias",
+	}
+	if indexOrAlias != "" {
+		r.IndexList = []string{indexOrAlias}
+	}
+
+	var resp map[string]struct {
+		Aliases map[string]AliasDefinition `json:"aliases"`
+	}
+	if err := c.doInto(&r, &resp); err != nil {
+		return nil, err
+	}
+
+	aliases := make(map[string]map[string]AliasDefinition, len(resp))
+	for index, definitions := range resp {
+		aliases[index] = definitions.Aliases
+	}
+
+	return aliases, nil
+}
+
+// ResolveAlias returns the sorted names of the indexes an alias points to.
+// An error with a 404 status code is returned if the alias does not exist.
+func (c *Client) ResolveAlias(alias string) ([]string, error) {
+	r := Request{
+		Method: "GET",
+		API:    "_alias/" + alias,
+	}
+
+	var resp map[string]interface{}
+	if err := c.doInto(&r, &resp); err != nil {
+		return nil, err
+	}
+
+	indexes := make([]string, 0, len(resp))
+	for index := range resp {
+		indexes = append(indexes, index)
+	}
+	sort.Strings(indexes)
+
+	return indexes, nil
+}
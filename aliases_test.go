@@ -0,0 +1,209 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	. "github.com/go-check/check"
+)
+
+func (s *GoesTestSuite) TestUpdateAliases(c *C) {
+	alias := "testupdatealiases"
+	oldIndex := "testupdatealiases_1"
+	newIndex := "testupdatealiases_2"
+
+	conn := NewClient(ESHost, ESPort)
+	// just in case
+	conn.DeleteIndex(oldIndex)
+	conn.DeleteIndex(newIndex)
+
+	for _, index := range []string{oldIndex, newIndex} {
+		_, err := conn.CreateIndex(index, map[string]interface{}{})
+		c.Assert(err, IsNil)
+		defer conn.DeleteIndex(index)
+	}
+
+	_, err := conn.AddAlias(alias, []string{oldIndex})
+	c.Assert(err, IsNil)
+
+	_, err = conn.UpdateAliases([]AliasAction{
+		{Action: AliasActionRemove, Index: oldIndex, Alias: alias},
+		{Action: AliasActionAdd, Index: newIndex, Alias: alias, Routing: "1"},
+	})
+	c.Assert(err, IsNil)
+
+	indexes, err := conn.ResolveAlias(alias)
+	c.Assert(err, IsNil)
+	c.Assert(indexes, DeepEquals, []string{newIndex})
+
+	// A failing action cancels the whole request
+	_, err = conn.UpdateAliases([]AliasAction{
+		{Action: AliasActionAdd, Index: oldIndex, Alias: alias},
+		{Action: AliasActionRemove, Index: "testupdatealiasesmissing", Alias: alias},
+	})
+	c.Assert(err, NotNil)
+
+	indexes, err = conn.ResolveAlias(alias)
+	c.Assert(err, IsNil)
+	c.Assert(indexes, DeepEquals, []string{newIndex})
+}
+
+func (s *GoesTestSuite) TestSwapAlias(c *C) {
+	alias := "testswapalias"
+	blueIndex := "testswapalias_blue"
+	greenIndex := "testswapalias_green"
+	docType := "tweet"
+
+	conn := NewClient(ESHost, ESPort)
+	if version, _ := conn.Version(); version < "2.3" {
+		c.Skip("Reindexing requires ES 2.3, skipping this test")
+	}
+	// just in case
+	conn.DeleteIndex(blueIndex)
+	conn.DeleteIndex(greenIndex)
+	defer conn.DeleteIndex(blueIndex)
+	defer conn.DeleteIndex(greenIndex)
+
+	// First deployment, nothing is behind the alias yet
+	_, err := conn.SwapAlias(AliasSwap{
+		Alias:    alias,
+		NewIndex: blueIndex,
+		Load: func(conn *Client, index string) error {
+			_, err := conn.BulkSend([]Document{
+				{Index: index, Type: docType, ID: "1", BulkCommand: BulkCommandIndex, Fields: map[string]interface{}{"user": "foo"}},
+				{Index: index, Type: docType, ID: "2", BulkCommand: BulkCommandIndex, Fields: map[string]interface{}{"user": "bar"}},
+			})
+			return err
+		},
+		Verify: func(newCount int, oldCount int) error {
+			c.Assert(oldCount, Equals, 0)
+			if newCount != 2 {
+				return errors.New("missing documents")
+			}
+			return nil
+		},
+	})
+	c.Assert(err, IsNil)
+
+	indexes, err := conn.ResolveAlias(alias)
+	c.Assert(err, IsNil)
+	c.Assert(indexes, DeepEquals, []string{blueIndex})
+
+	// A failed verification leaves the alias untouched
+	_, err = conn.SwapAlias(AliasSwap{
+		Alias:    alias,
+		NewIndex: greenIndex,
+		Load:     func(conn *Client, index string) error { return nil },
+	})
+	c.Assert(err, ErrorMatches, ".* holds 0 documents instead of 2")
+
+	indexes, err = conn.ResolveAlias(alias)
+	c.Assert(err, IsNil)
+	c.Assert(indexes, DeepEquals, []string{blueIndex})
+
+	// Reindex from blue to green
+	_, err = conn.SwapAlias(AliasSwap{
+		Alias:     alias,
+		NewIndex:  greenIndex,
+		DeleteOld: true,
+	})
+	c.Assert(err, IsNil)
+
+	indexes, err = conn.ResolveAlias(alias)
+	c.Assert(err, IsNil)
+	c.Assert(indexes, DeepEquals, []string{greenIndex})
+
+	exists, _ := conn.IndicesExist([]string{blueIndex})
+	c.Assert(exists, Equals, false)
+
+	response, err := conn.Get(alias, docType, "2", url.Values{})
+	c.Assert(err, IsNil)
+	c.Assert(response.Source["user"], Equals, "bar")
+}
+
+func (s *GoesTestSuite) TestSwapAliasReindexFailures(c *C) {
+	for _, reindexResp := range []string{
+		`{"total": 2, "created": 1, "failures": [{"index": "green", "id": "2", "status": 400}]}`,
+		`{"total": 2, "created": 1, "timed_out": true, "failures": []}`,
+	} {
+		var requests []string
+		ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/_alias/tweets":
+				w.Write([]byte(`{"blue": {"aliases": {"tweets": {}}}}`))
+			case "/_reindex":
+				w.Write([]byte(reindexResp))
+			default:
+				w.Write([]byte(`{"acknowledged": true}`))
+			}
+		})
+
+		_, err := conn.SwapAlias(AliasSwap{
+			Alias:    "tweets",
+			NewIndex: "green",
+			Verify:   func(newCount int, oldCount int) error { return nil },
+		})
+		c.Check(err, ErrorMatches, "Reindexing into green (failed for 1 documents|timed out).*")
+		c.Check(requests, DeepEquals, []string{
+			"GET /_alias/tweets",
+			"PUT /green/",
+			"POST /_reindex",
+			"DELETE /green/",
+		})
+		ts.Close()
+	}
+}
+
+func (s *GoesTestSuite) TestGetAliases(c *C) {
+	alias := "testgetaliases"
+	filtered := "testgetaliases_filtered"
+	indexName := "testgetaliases_1"
+
+	conn := NewClient(ESHost, ESPort)
+	// just in case
+	conn.DeleteIndex(indexName)
+
+	_, err := conn.ResolveAlias(alias)
+	c.Assert(err, ErrorMatches, `\[404\] .*`)
+
+	_, err = conn.CreateIndex(indexName, map[string]interface{}{})
+	c.Assert(err, IsNil)
+	defer conn.DeleteIndex(indexName)
+
+	_, err = conn.UpdateAliases([]AliasAction{
+		{Action: AliasActionAdd, Index: indexName, Alias: alias},
+		{
+			Action:  AliasActionAdd,
+			Index:   indexName,
+			Alias:   filtered,
+			Routing: "1",
+			Filter: map[string]interface{}{
+				"term": map[string]interface{}{"user": "foo"},
+			},
+		},
+	})
+	c.Assert(err, IsNil)
+
+	indexes, err := conn.ResolveAlias(alias)
+	c.Assert(err, IsNil)
+	c.Assert(indexes, DeepEquals, []string{indexName})
+
+	aliases, err := conn.GetAliases(alias)
+	c.Assert(err, IsNil)
+	c.Assert(aliases[indexName], HasLen, 2)
+	c.Assert(aliases[indexName][alias], DeepEquals, AliasDefinition{})
+	c.Assert(aliases[indexName][filtered], DeepEquals, AliasDefinition{
+		Filter: map[string]interface{}{
+			"term": map[string]interface{}{"user": "foo"},
+		},
+		IndexRouting:  "1",
+		SearchRouting: "1",
+	})
+}
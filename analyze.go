@@ -0,0 +1,114 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import "encoding/json"
+
+// AnalyzeRequest describes the analysis of a text. Analyzer names an analyzer
+// of the index or a built-in one. Without Analyzer, the text goes through
+// Tokenizer, Filter and CharFilter, each entry being the name of a built-in
+// component, of a component of the index, or the map definition of an ad-hoc
+// one.
+type AnalyzeRequest struct {
+	Text       []string      `json:"text"`
+	Analyzer   string        `json:"analyzer,omitempty"`
+	Tokenizer  interface{}   `json:"tokenizer,omitempty"`
+	Filter     []interface{} `json:"filter,omitempty"`
+	CharFilter []interface{} `json:"char_filter,omitempty"`
+
+	// Field uses the analyzer of a field of the index
+	Field string `json:"field,omitempty"`
+
+	// Normalizer applies a keyword normalizer instead of analyzing the text
+	Normalizer string `json:"normalizer,omitempty"`
+
+	// Explain returns the tokens after each step of the analysis, optionally
+	// restricted to the token Attributes
+	Explain    bool     `json:"explain,omitempty"`
+	Attributes []string `json:"attributes,omitempty"`
+}
+
+// AnalyzeToken is a token produced by an analysis
+type AnalyzeToken struct {
+	Token          string `json:"token"`
+	StartOffset    int    `json:"start_offset"`
+	EndOffset      int    `json:"end_offset"`
+	Type           string `json:"type"`
+	Position       int    `json:"position"`
+	PositionLength int    `json:"positionLength"`
+
+	// Attributes holds the token attributes requested by an explain, e.g.
+	// keyword or termFrequency
+	Attributes map[string]interface{} `json:"-"`
+}
+
+// UnmarshalJSON decodes a token, gathering its extra attributes in Attributes
+func (t *AnalyzeToken) UnmarshalJSON(data []byte) error {
+	type token AnalyzeToken
+	if err := json.Unmarshal(data, (*token)(t)); err != nil {
+		return err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for _, known := range []string{"token", "start_offset", "end_offset", "type", "position", "positionLength"} {
+		delete(fields, known)
+	}
+	if len(fields) > 0 {
+		t.Attributes = fields
+	}
+
+	return nil
+}
+
+// AnalyzeComponent holds the tokens output by a component of an explained
+// analysis
+type AnalyzeComponent struct {
+	Name   string         `json:"name"`
+	Tokens []AnalyzeToken `json:"tokens"`
+}
+
+// AnalyzeCharFilter holds the text output by a char filter of an explained
+// analysis
+type AnalyzeCharFilter struct {
+	Name         string   `json:"name"`
+	FilteredText []string `json:"filtered_text"`
+}
+
+// AnalyzeDetail holds an explained analysis, step by step. Analyzer is set
+// when a named analyzer was used, the other fields otherwise.
+type AnalyzeDetail struct {
+	CustomAnalyzer bool                `json:"custom_analyzer"`
+	Analyzer       *AnalyzeComponent   `json:"analyzer"`
+	CharFilters    []AnalyzeCharFilter `json:"charfilters"`
+	Tokenizer      *AnalyzeComponent   `json:"tokenizer"`
+	TokenFilters   []AnalyzeComponent  `json:"tokenfilters"`
+}
+
+// AnalyzeResponse holds the result of an analysis. Tokens is empty for an
+// explained analysis, which fills Detail instead.
+type AnalyzeResponse struct {
+	Tokens []AnalyzeToken `json:"tokens"`
+	Detail *AnalyzeDetail `json:"detail"`
+}
+
+// Analyze runs a text through an analyzer, or through an ad-hoc chain of
+// tokenizer, token filters and char filters, and returns the produced tokens.
+// index may be empty when only built-in components are used.
+func (c *Client) Analyze(index string, analysis AnalyzeRequest) (*AnalyzeResponse, error) {
+	r := Request{
+		Query:  analysis,
+		Method: "POST",
+		API:    "_analyze",
+	}
+	if index != "" {
+		r.IndexList = []string{index}
+	}
+
+	resp := &AnalyzeResponse{}
+	return resp, c.doInto(&r, resp)
+}
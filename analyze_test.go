@@ -0,0 +1,120 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"encoding/json"
+	"net/http"
+
+	. "github.com/go-check/check"
+)
+
+func (s *GoesTestSuite) TestAnalyze(c *C) {
+	indexName := "testanalyze"
+
+	conn := NewClient(ESHost, ESPort)
+	if supported, _ := conn.versionAtLeast("5"); !supported {
+		c.Skip("Ad-hoc analysis components require ES 5, skipping this test")
+	}
+	conn.DeleteIndex(indexName)
+
+	_, err := conn.CreateIndex(indexName, map[string]interface{}{
+		"settings": map[string]interface{}{
+			"analysis": map[string]interface{}{
+				"analyzer": map[string]interface{}{
+					"folding": map[string]interface{}{
+						"tokenizer": "standard",
+						"filter":    []string{"lowercase", "asciifolding"},
+					},
+				},
+			},
+		},
+	})
+	c.Assert(err, IsNil)
+	defer conn.DeleteIndex(indexName)
+
+	resp, err := conn.Analyze(indexName, AnalyzeRequest{Text: []string{"Crème Brûlée"}, Analyzer: "folding"})
+	c.Assert(err, IsNil)
+	c.Assert(resp.Tokens, DeepEquals, []AnalyzeToken{
+		{Token: "creme", StartOffset: 0, EndOffset: 5, Type: "<ALPHANUM>", Position: 0},
+		{Token: "brulee", StartOffset: 6, EndOffset: 12, Type: "<ALPHANUM>", Position: 1},
+	})
+
+	// Ad-hoc components, without index
+	resp, err = conn.Analyze("", AnalyzeRequest{
+		Text:       []string{"<b>Quick</b> foxes"},
+		Tokenizer:  "whitespace",
+		CharFilter: []interface{}{"html_strip"},
+		Filter: []interface{}{
+			"lowercase",
+			map[string]interface{}{"type": "stop", "stopwords": []string{"quick"}},
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(resp.Tokens, HasLen, 1)
+	c.Assert(resp.Tokens[0].Token, Equals, "foxes")
+	c.Assert(resp.Tokens[0].Position, Equals, 1)
+
+	resp, err = conn.Analyze(indexName, AnalyzeRequest{
+		Text:       []string{"Brûlée"},
+		Analyzer:   "folding",
+		Explain:    true,
+		Attributes: []string{"keyword"},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(resp.Tokens, HasLen, 0)
+	c.Assert(resp.Detail.CustomAnalyzer, Equals, false)
+	c.Assert(resp.Detail.Analyzer.Name, Equals, "folding")
+	c.Assert(resp.Detail.Analyzer.Tokens, HasLen, 1)
+	c.Assert(resp.Detail.Analyzer.Tokens[0].Token, Equals, "brulee")
+	c.Assert(resp.Detail.Analyzer.Tokens[0].Attributes["keyword"], Equals, false)
+}
+
+func (s *GoesTestSuite) TestAnalyzeExplainDecode(c *C) {
+	var body map[string]interface{}
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/_analyze")
+		c.Check(json.NewDecoder(r.Body).Decode(&body), IsNil)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"detail": {
+			"custom_analyzer": true,
+			"charfilters": [{"name": "html_strip", "filtered_text": ["Quick foxes"]}],
+			"tokenizer": {"name": "standard", "tokens": [
+				{"token": "Quick", "start_offset": 3, "end_offset": 8, "type": "<ALPHANUM>", "position": 0, "bytes": "[51 75]", "positionLength": 1}
+			]},
+			"tokenfilters": [{"name": "lowercase", "tokens": [
+				{"token": "quick", "start_offset": 3, "end_offset": 8, "type": "<ALPHANUM>", "position": 0, "keyword": false}
+			]}]
+		}}`))
+	})
+	defer ts.Close()
+
+	resp, err := conn.Analyze("", AnalyzeRequest{
+		Text:       []string{"<b>Quick</b> foxes"},
+		Tokenizer:  "standard",
+		Filter:     []interface{}{"lowercase"},
+		CharFilter: []interface{}{"html_strip"},
+		Explain:    true,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(body, DeepEquals, map[string]interface{}{
+		"text":        []interface{}{"<b>Quick</b> foxes"},
+		"tokenizer":   "standard",
+		"filter":      []interface{}{"lowercase"},
+		"char_filter": []interface{}{"html_strip"},
+		"explain":     true,
+	})
+
+	detail := resp.Detail
+	c.Assert(detail.CustomAnalyzer, Equals, true)
+	c.Assert(detail.Analyzer, IsNil)
+	c.Assert(detail.CharFilters, DeepEquals, []AnalyzeCharFilter{{Name: "html_strip", FilteredText: []string{"Quick foxes"}}})
+	c.Assert(detail.Tokenizer.Tokens, DeepEquals, []AnalyzeToken{{
+		Token: "Quick", StartOffset: 3, EndOffset: 8, Type: "<ALPHANUM>", PositionLength: 1,
+		Attributes: map[string]interface{}{"bytes": "[51 75]"},
+	}})
+	c.Assert(detail.TokenFilters, HasLen, 1)
+	c.Assert(detail.TokenFilters[0].Tokens[0].Attributes, DeepEquals, map[string]interface{}{"keyword": false})
+}
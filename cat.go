@@ -0,0 +1,162 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"net/url"
+	"strings"
+)
+
+// CatClient queries the _cat APIs, which are meant for humans but are
+// requested here as JSON. Get one with Client.Cat.
+type CatClient struct {
+	client *Client
+}
+
+// CatOptions selects and sorts the columns returned by a _cat API
+type CatOptions struct {
+	// Columns to return (h), the default ones of the API when empty
+	Columns []string
+
+	// Sort the rows by these columns (s), a column may be suffixed with :desc
+	Sort []string
+
+	// ExtraArgs are added to the query string, e.g. bytes=b to get sizes
+	// in bytes rather than human readable
+	ExtraArgs url.Values
+}
+
+// CatRow holds a row of any _cat API, by column name. Missing values are
+// empty strings.
+type CatRow map[string]string
+
+// CatIndex holds a row of _cat/indices. Counts are zero for closed indexes.
+type CatIndex struct {
+	Health       string `json:"health"`
+	Status       string `json:"status"`
+	Index        string `json:"index"`
+	UUID         string `json:"uuid"`
+	Pri          int    `json:"pri,string"`
+	Rep          int    `json:"rep,string"`
+	DocsCount    int64  `json:"docs.count,string"`
+	DocsDeleted  int64  `json:"docs.deleted,string"`
+	StoreSize    string `json:"store.size"`
+	PriStoreSize string `json:"pri.store.size"`
+}
+
+// CatShard holds a row of _cat/shards. UnassignedReason is only set when the
+// unassigned.reason column is requested.
+type CatShard struct {
+	Index            string `json:"index"`
+	Shard            int    `json:"shard,string"`
+	PriRep           string `json:"prirep"`
+	State            string `json:"state"`
+	Docs             int64  `json:"docs,string"`
+	Store            string `json:"store"`
+	IP               string `json:"ip"`
+	Node             string `json:"node"`
+	UnassignedReason string `json:"unassigned.reason"`
+}
+
+// CatAllocation holds a row of _cat/allocation. The shards which are not
+// allocated are counted in a row with the UNASSIGNED node.
+type CatAllocation struct {
+	Shards      int    `json:"shards,string"`
+	DiskIndices string `json:"disk.indices"`
+	DiskUsed    string `json:"disk.used"`
+	DiskAvail   string `json:"disk.avail"`
+	DiskTotal   string `json:"disk.total"`
+	DiskPercent int    `json:"disk.percent,string"`
+	Host        string `json:"host"`
+	IP          string `json:"ip"`
+	Node        string `json:"node"`
+}
+
+// CatNode holds a row of _cat/nodes. Master is "*" for the elected master.
+type CatNode struct {
+	IP          string `json:"ip"`
+	HeapPercent int    `json:"heap.percent,string"`
+	RAMPercent  int    `json:"ram.percent,string"`
+	CPU         int    `json:"cpu,string"`
+	Load1m      string `json:"load_1m"`
+	Load5m      string `json:"load_5m"`
+	Load15m     string `json:"load_15m"`
+	NodeRole    string `json:"node.role"`
+	Master      string `json:"master"`
+	Name        string `json:"name"`
+}
+
+// CatThreadPool holds a row of _cat/thread_pool
+type CatThreadPool struct {
+	NodeName string `json:"node_name"`
+	Name     string `json:"name"`
+	Active   int    `json:"active,string"`
+	Queue    int    `json:"queue,string"`
+	Rejected int64  `json:"rejected,string"`
+}
+
+// Cat returns a client for the _cat APIs
+func (c *Client) Cat() *CatClient {
+	return &CatClient{client: c}
+}
+
+// Indices returns a row per index of indexList, all of them if it is empty
+func (cat *CatClient) Indices(indexList []string, options CatOptions) ([]CatIndex, error) {
+	var rows []CatIndex
+	return rows, cat.rows(namedAPI("_cat/indices", indexList), options, &rows)
+}
+
+// Shards returns a row per shard of the indexes of indexList, all of them if
+// it is empty
+func (cat *CatClient) Shards(indexList []string, options CatOptions) ([]CatShard, error) {
+	var rows []CatShard
+	return rows, cat.rows(namedAPI("_cat/shards", indexList), options, &rows)
+}
+
+// Allocation returns the number of shards and the disk usage of the nodes of
+// nodeList, all of them if it is empty
+func (cat *CatClient) Allocation(nodeList []string, options CatOptions) ([]CatAllocation, error) {
+	var rows []CatAllocation
+	return rows, cat.rows(namedAPI("_cat/allocation", nodeList), options, &rows)
+}
+
+// Nodes returns a row per node of the cluster
+func (cat *CatClient) Nodes(options CatOptions) ([]CatNode, error) {
+	var rows []CatNode
+	return rows, cat.rows("_cat/nodes", options, &rows)
+}
+
+// ThreadPool returns a row per node and thread pool of poolList, all of them
+// if it is empty
+func (cat *CatClient) ThreadPool(poolList []string, options CatOptions) ([]CatThreadPool, error) {
+	var rows []CatThreadPool
+	return rows, cat.rows(namedAPI("_cat/thread_pool", poolList), options, &rows)
+}
+
+// Rows returns the rows of any _cat API, api being the path after _cat/, e.g.
+// "aliases" or "recovery/myindex"
+func (cat *CatClient) Rows(api string, options CatOptions) ([]CatRow, error) {
+	var rows []CatRow
+	return rows, cat.rows("_cat/"+api, options, &rows)
+}
+
+func (cat *CatClient) rows(api string, options CatOptions, v interface{}) error {
+	args := copyArgs(options.ExtraArgs, 3)
+	args.Set("format", "json")
+	if len(options.Columns) > 0 {
+		args.Set("h", strings.Join(options.Columns, ","))
+	}
+	if len(options.Sort) > 0 {
+		args.Set("s", strings.Join(options.Sort, ","))
+	}
+
+	r := Request{
+		Method:    "GET",
+		API:       api,
+		ExtraArgs: args,
+	}
+
+	return cat.client.doInto(&r, v)
+}
@@ -0,0 +1,120 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"net/http"
+	"net/url"
+
+	. "github.com/go-check/check"
+)
+
+func (s *GoesTestSuite) TestCatIndices(c *C) {
+	indexName := "testcatindices"
+	docType := "tweet"
+
+	conn := NewClient(ESHost, ESPort)
+	if supported, _ := conn.versionAtLeast("5.1"); !supported {
+		c.Skip("Sorting _cat APIs requires ES 5.1, skipping this test")
+	}
+	conn.DeleteIndex(indexName)
+
+	_, err := conn.CreateIndex(indexName, map[string]interface{}{
+		"settings": map[string]interface{}{
+			"index.number_of_shards":   1,
+			"index.number_of_replicas": 0,
+		},
+	})
+	c.Assert(err, IsNil)
+	defer conn.DeleteIndex(indexName)
+
+	_, err = conn.Index(Document{
+		Index:  indexName,
+		Type:   docType,
+		ID:     "1",
+		Fields: map[string]interface{}{"user": "foo"},
+	}, url.Values{"refresh": []string{"true"}})
+	c.Assert(err, IsNil)
+
+	indices, err := conn.Cat().Indices([]string{indexName}, CatOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(indices, HasLen, 1)
+	c.Assert(indices[0].Index, Equals, indexName)
+	c.Assert(indices[0].Health, Equals, "green")
+	c.Assert(indices[0].Pri, Equals, 1)
+	c.Assert(indices[0].DocsCount, Equals, int64(1))
+
+	shards, err := conn.Cat().Shards([]string{indexName}, CatOptions{
+		Columns: []string{"index", "shard", "prirep", "state", "docs"},
+		Sort:    []string{"shard"},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(shards, DeepEquals, []CatShard{{Index: indexName, Shard: 0, PriRep: "p", State: "STARTED", Docs: 1}})
+
+	nodes, err := conn.Cat().Nodes(CatOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(len(nodes) >= 1, Equals, true)
+	c.Assert(nodes[0].Name, Not(Equals), "")
+
+	rows, err := conn.Cat().Rows("count/"+indexName, CatOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(rows, HasLen, 1)
+	c.Assert(rows[0]["count"], Equals, "1")
+}
+
+func (s *GoesTestSuite) TestCatRequest(c *C) {
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/_cat/thread_pool/search,write")
+		c.Check(r.URL.Query(), DeepEquals, url.Values{
+			"format": []string{"json"},
+			"h":      []string{"node_name,name,active,queue,rejected"},
+			"s":      []string{"rejected:desc,name"},
+			"v":      []string{"true"},
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"node_name": "n1", "name": "write", "active": "2", "queue": "10", "rejected": "42"},
+			{"node_name": "n1", "name": "search", "active": "0", "queue": "0", "rejected": "0"}
+		]`))
+	})
+	defer ts.Close()
+
+	extraArgs := url.Values{"v": []string{"true"}}
+	pools, err := conn.Cat().ThreadPool([]string{"search", "write"}, CatOptions{
+		Columns:   []string{"node_name", "name", "active", "queue", "rejected"},
+		Sort:      []string{"rejected:desc", "name"},
+		ExtraArgs: extraArgs,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(pools, DeepEquals, []CatThreadPool{
+		{NodeName: "n1", Name: "write", Active: 2, Queue: 10, Rejected: 42},
+		{NodeName: "n1", Name: "search"},
+	})
+	c.Assert(extraArgs, HasLen, 1)
+}
+
+func (s *GoesTestSuite) TestCatAllocation(c *C) {
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/_cat/allocation")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"shards": "5", "disk.indices": "1kb", "disk.used": "10gb", "disk.avail": "90gb", "disk.total": "100gb", "disk.percent": "10", "host": "h1", "ip": "10.0.0.1", "node": "n1"},
+			{"shards": "2", "disk.indices": null, "disk.used": null, "disk.avail": null, "disk.total": null, "disk.percent": null, "host": null, "ip": null, "node": "UNASSIGNED"}
+		]`))
+	})
+	defer ts.Close()
+
+	allocation, err := conn.Cat().Allocation(nil, CatOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(allocation, HasLen, 2)
+	c.Assert(allocation[0].DiskPercent, Equals, 10)
+	c.Assert(allocation[0].DiskAvail, Equals, "90gb")
+	c.Assert(allocation[1], Equals, CatAllocation{Shards: 2, Node: "UNASSIGNED"})
+
+	rows, err := conn.Cat().Rows("allocation", CatOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(rows[1]["disk.percent"], Equals, "")
+	c.Assert(rows[1]["node"], Equals, "UNASSIGNED")
+}
@@ -0,0 +1,196 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"net/url"
+	"strings"
+)
+
+// ShardHealth holds the health of a shard, with level=shards
+type ShardHealth struct {
+	Status             string `json:"status"`
+	PrimaryActive      bool   `json:"primary_active"`
+	ActiveShards       int    `json:"active_shards"`
+	RelocatingShards   int    `json:"relocating_shards"`
+	InitializingShards int    `json:"initializing_shards"`
+	UnassignedShards   int    `json:"unassigned_shards"`
+}
+
+// IndexHealth holds the health of an index, with level=indices or level=shards
+type IndexHealth struct {
+	Status              string                 `json:"status"`
+	NumberOfShards      int                    `json:"number_of_shards"`
+	NumberOfReplicas    int                    `json:"number_of_replicas"`
+	ActivePrimaryShards int                    `json:"active_primary_shards"`
+	ActiveShards        int                    `json:"active_shards"`
+	RelocatingShards    int                    `json:"relocating_shards"`
+	InitializingShards  int                    `json:"initializing_shards"`
+	UnassignedShards    int                    `json:"unassigned_shards"`
+	Shards              map[string]ShardHealth `json:"shards"`
+}
+
+// ClusterHealth holds the health of the cluster as returned by _cluster/health
+type ClusterHealth struct {
+	ClusterName                 string                 `json:"cluster_name"`
+	Status                      string                 `json:"status"`
+	TimedOut                    bool                   `json:"timed_out"`
+	NumberOfNodes               int                    `json:"number_of_nodes"`
+	NumberOfDataNodes           int                    `json:"number_of_data_nodes"`
+	ActivePrimaryShards         int                    `json:"active_primary_shards"`
+	ActiveShards                int                    `json:"active_shards"`
+	RelocatingShards            int                    `json:"relocating_shards"`
+	InitializingShards          int                    `json:"initializing_shards"`
+	UnassignedShards            int                    `json:"unassigned_shards"`
+	DelayedUnassignedShards     int                    `json:"delayed_unassigned_shards"`
+	NumberOfPendingTasks        int                    `json:"number_of_pending_tasks"`
+	NumberOfInFlightFetch       int                    `json:"number_of_in_flight_fetch"`
+	TaskMaxWaitingInQueueMillis int64                  `json:"task_max_waiting_in_queue_millis"`
+	ActiveShardsPercent         float64                `json:"active_shards_percent_as_number"`
+	Indices                     map[string]IndexHealth `json:"indices"`
+}
+
+// ClusterHealthOptions holds the parameters of ClusterHealth
+type ClusterHealthOptions struct {
+	// Level of detail, "indices" to get the health of every index or
+	// "shards" to get the health of every shard too
+	Level string
+
+	// WaitForStatus waits until the status is at least "yellow" or "green"
+	WaitForStatus string
+
+	// WaitForNodes waits until that many nodes are in the cluster, either a
+	// number or a comparison such as ">=3"
+	WaitForNodes string
+
+	// WaitForActiveShards waits until that many shards, or "all", are active
+	WaitForActiveShards string
+
+	// WaitForNoRelocatingShards waits until no shard is relocating
+	WaitForNoRelocatingShards bool
+
+	// Timeout of the waits, e.g. "30s"
+	Timeout string
+
+	// ExtraArgs are added to the query string
+	ExtraArgs url.Values
+}
+
+// ClusterStateNode describes a node in the cluster state
+type ClusterStateNode struct {
+	Name             string            `json:"name"`
+	EphemeralID      string            `json:"ephemeral_id"`
+	TransportAddress string            `json:"transport_address"`
+	Attributes       map[string]string `json:"attributes"`
+}
+
+// ClusterState holds the parts of the cluster state selected when calling
+// ClusterState
+type ClusterState struct {
+	ClusterName  string                      `json:"cluster_name"`
+	ClusterUUID  string                      `json:"cluster_uuid"`
+	Version      int64                       `json:"version"`
+	StateUUID    string                      `json:"state_uuid"`
+	MasterNode   string                      `json:"master_node"`
+	Blocks       map[string]interface{}      `json:"blocks"`
+	Nodes        map[string]ClusterStateNode `json:"nodes"`
+	Metadata     map[string]interface{}      `json:"metadata"`
+	RoutingTable map[string]interface{}      `json:"routing_table"`
+	RoutingNodes map[string]interface{}      `json:"routing_nodes"`
+}
+
+// ClusterSettings holds the persistent and transient settings of the cluster.
+// Defaults is only returned by GetClusterSettings with include_defaults=true.
+type ClusterSettings struct {
+	Persistent map[string]interface{} `json:"persistent,omitempty"`
+	Transient  map[string]interface{} `json:"transient,omitempty"`
+	Defaults   map[string]interface{} `json:"defaults,omitempty"`
+}
+
+// ClusterHealth returns the health of the cluster, or of the indexes in
+// indexList only, once the waits of the options are over. If a wait times
+// out, the health is returned along with an error.
+func (c *Client) ClusterHealth(indexList []string, options ClusterHealthOptions) (*ClusterHealth, error) {
+	args := copyArgs(options.ExtraArgs, 6)
+	if options.Level != "" {
+		args.Set("level", options.Level)
+	}
+	if options.WaitForStatus != "" {
+		args.Set("wait_for_status", options.WaitForStatus)
+	}
+	if options.WaitForNodes != "" {
+		args.Set("wait_for_nodes", options.WaitForNodes)
+	}
+	if options.WaitForActiveShards != "" {
+		args.Set("wait_for_active_shards", options.WaitForActiveShards)
+	}
+	if options.WaitForNoRelocatingShards {
+		args.Set("wait_for_no_relocating_shards", "true")
+	}
+	if options.Timeout != "" {
+		args.Set("timeout", options.Timeout)
+	}
+
+	r := Request{
+		Method:    "GET",
+		API:       "_cluster/health",
+		ExtraArgs: args,
+	}
+	if len(indexList) > 0 {
+		r.API += "/" + strings.Join(indexList, ",")
+	}
+
+	health := &ClusterHealth{}
+	return health, c.doInto(&r, health)
+}
+
+// ClusterState returns the metrics (e.g. nodes, metadata, routing_table) of
+// the cluster state, restricted to the indexes in indexList. Everything is
+// returned if both are empty.
+func (c *Client) ClusterState(metrics []string, indexList []string, extraArgs url.Values) (*ClusterState, error) {
+	r := Request{
+		Method:    "GET",
+		API:       "_cluster/state",
+		ExtraArgs: extraArgs,
+	}
+
+	if len(metrics) == 0 && len(indexList) > 0 {
+		metrics = []string{"_all"}
+	}
+	if len(metrics) > 0 {
+		r.API += "/" + strings.Join(metrics, ",")
+	}
+	if len(indexList) > 0 {
+		r.API += "/" + strings.Join(indexList, ",")
+	}
+
+	state := &ClusterState{}
+	return state, c.doInto(&r, state)
+}
+
+// GetClusterSettings returns the settings of the cluster. Use flat_settings
+// and include_defaults in extraArgs to change what is returned.
+func (c *Client) GetClusterSettings(extraArgs url.Values) (*ClusterSettings, error) {
+	r := Request{
+		Method:    "GET",
+		API:       "_cluster/settings",
+		ExtraArgs: extraArgs,
+	}
+
+	settings := &ClusterSettings{}
+	return settings, c.doInto(&r, settings)
+}
+
+// PutClusterSettings updates the persistent and transient settings of the
+// cluster. A setting is reset to its default by setting it to nil.
+func (c *Client) PutClusterSettings(settings ClusterSettings) (*Response, error) {
+	r := Request{
+		Query:  settings,
+		Method: "PUT",
+		API:    "_cluster/settings",
+	}
+
+	return c.Do(&r)
+}
@@ -0,0 +1,116 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"net/http"
+	"net/url"
+
+	. "github.com/go-check/check"
+)
+
+func (s *GoesTestSuite) TestClusterHealth(c *C) {
+	indexName := "testclusterhealth"
+
+	conn := NewClient(ESHost, ESPort)
+	conn.DeleteIndex(indexName)
+
+	_, err := conn.CreateIndex(indexName, map[string]interface{}{
+		"settings": map[string]interface{}{
+			"index.number_of_shards":   1,
+			"index.number_of_replicas": 0,
+		},
+	})
+	c.Assert(err, IsNil)
+	defer conn.DeleteIndex(indexName)
+
+	health, err := conn.ClusterHealth(nil, ClusterHealthOptions{WaitForStatus: "yellow"})
+	c.Assert(err, IsNil)
+	c.Assert(health.TimedOut, Equals, false)
+	c.Assert(health.Status, Not(Equals), "red")
+	c.Assert(health.NumberOfNodes >= 1, Equals, true)
+	c.Assert(health.Indices, IsNil)
+
+	health, err = conn.ClusterHealth([]string{indexName}, ClusterHealthOptions{
+		WaitForStatus: "green",
+		Level:         "shards",
+	})
+	c.Assert(err, IsNil)
+	c.Assert(health.Status, Equals, "green")
+	c.Assert(health.Indices, HasLen, 1)
+	c.Assert(health.Indices[indexName].NumberOfShards, Equals, 1)
+	c.Assert(health.Indices[indexName].Shards["0"].PrimaryActive, Equals, true)
+}
+
+func (s *GoesTestSuite) TestClusterHealthTimeout(c *C) {
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/_cluster/health/a,b")
+		c.Check(r.URL.Query(), DeepEquals, url.Values{
+			"wait_for_status":               []string{"green"},
+			"wait_for_nodes":                []string{">=3"},
+			"wait_for_no_relocating_shards": []string{"true"},
+			"timeout":                       []string{"1s"},
+			"local":                         []string{"true"},
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusRequestTimeout)
+		w.Write([]byte(`{"cluster_name": "goes", "status": "yellow", "timed_out": true, "unassigned_shards": 5}`))
+	})
+	defer ts.Close()
+
+	health, err := conn.ClusterHealth([]string{"a", "b"}, ClusterHealthOptions{
+		WaitForStatus:             "green",
+		WaitForNodes:              ">=3",
+		WaitForNoRelocatingShards: true,
+		Timeout:                   "1s",
+		ExtraArgs:                 url.Values{"local": []string{"true"}},
+	})
+	c.Assert(err, ErrorMatches, `\[408\] .*`)
+	c.Assert(health.TimedOut, Equals, true)
+	c.Assert(health.Status, Equals, "yellow")
+	c.Assert(health.UnassignedShards, Equals, 5)
+}
+
+func (s *GoesTestSuite) TestClusterState(c *C) {
+	indexName := "testclusterstate"
+
+	conn := NewClient(ESHost, ESPort)
+	conn.DeleteIndex(indexName)
+
+	_, err := conn.CreateIndex(indexName, map[string]interface{}{})
+	c.Assert(err, IsNil)
+	defer conn.DeleteIndex(indexName)
+
+	state, err := conn.ClusterState([]string{"master_node", "nodes"}, nil, url.Values{})
+	c.Assert(err, IsNil)
+	c.Assert(state.MasterNode, Not(Equals), "")
+	c.Assert(state.Nodes[state.MasterNode].Name, Not(Equals), "")
+	c.Assert(state.Metadata, IsNil)
+
+	state, err = conn.ClusterState([]string{"metadata"}, []string{indexName}, url.Values{})
+	c.Assert(err, IsNil)
+	indices := state.Metadata["indices"].(map[string]interface{})
+	c.Assert(indices, HasLen, 1)
+	c.Assert(indices[indexName], NotNil)
+}
+
+func (s *GoesTestSuite) TestClusterSettings(c *C) {
+	setting := "cluster.routing.allocation.enable"
+
+	conn := NewClient(ESHost, ESPort)
+
+	_, err := conn.PutClusterSettings(ClusterSettings{
+		Transient: map[string]interface{}{setting: "primaries"},
+	})
+	c.Assert(err, IsNil)
+	defer conn.PutClusterSettings(ClusterSettings{
+		Transient: map[string]interface{}{setting: "all"},
+	})
+
+	settings, err := conn.GetClusterSettings(url.Values{"flat_settings": []string{"true"}})
+	c.Assert(err, IsNil)
+	c.Assert(settings.Transient[setting], Equals, "primaries")
+	c.Assert(settings.Defaults, IsNil)
+}
@@ -0,0 +1,148 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// CountOptions holds the parameters of a count
+type CountOptions struct {
+	// TerminateAfter stops counting on each shard once that many documents
+	// matched, the count is then a lower bound
+	TerminateAfter int
+
+	// MinScore only counts the documents scoring at least that much
+	MinScore float64
+
+	// Routing restricts the count to the shards of these routing values
+	Routing []string
+
+	// QueryString is a query in the Lucene syntax (q), used instead of the
+	// query of the request body
+	QueryString string
+
+	// Approximate counts with a search tracking the total of hits up to that
+	// many documents, the count is then a lower bound. Counting stops earlier
+	// than with TerminateAfter, but this requires ES 7: before, the count is
+	// exact.
+	Approximate int
+
+	// ExtraArgs are added to the query string
+	ExtraArgs url.Values
+}
+
+// CountResponse holds the result of a count
+type CountResponse struct {
+	Count uint64 `json:"count"`
+
+	// One of the TotalHits* constants
+	Relation string `json:"-"`
+
+	// Set when the count was stopped by TerminateAfter
+	TerminatedEarly bool  `json:"terminated_early"`
+	Shards          Shard `json:"_shards"`
+}
+
+// Exact returns whether Count is the exact number of matching documents,
+// rather than a lower bound
+func (r *CountResponse) Exact() bool {
+	return r.Relation == TotalHitsEqual
+}
+
+// CountWithOptions counts the documents matching query, like Count, with the
+// given options. query may be nil to count all documents.
+func (c *Client) CountWithOptions(query interface{}, indexList []string, typeList []string, options CountOptions) (*CountResponse, error) {
+	args := copyArgs(options.ExtraArgs, 4)
+	if options.TerminateAfter > 0 {
+		args.Set("terminate_after", strconv.Itoa(options.TerminateAfter))
+	}
+	if len(options.Routing) > 0 {
+		args.Set("routing", strings.Join(options.Routing, ","))
+	}
+	if options.QueryString != "" {
+		args.Set("q", options.QueryString)
+	}
+
+	if options.Approximate > 0 {
+		if supported, err := c.versionAtLeast("7"); err != nil {
+			return nil, err
+		} else if supported {
+			return c.approximateCount(query, indexList, typeList, options, args)
+		}
+	}
+
+	if options.MinScore != 0 {
+		args.Set("min_score", strconv.FormatFloat(options.MinScore, 'f', -1, 64))
+	}
+
+	r := Request{
+		Query:     query,
+		IndexList: indexList,
+		TypeList:  typeList,
+		Method:    "POST",
+		API:       "_count",
+		ExtraArgs: args,
+	}
+
+	resp := &CountResponse{}
+	if err := c.doInto(&r, resp); err != nil {
+		return nil, err
+	}
+
+	resp.Relation = TotalHitsEqual
+	if resp.TerminatedEarly {
+		resp.Relation = TotalHitsGreaterOrEqual
+	}
+
+	return resp, nil
+}
+
+// approximateCount counts with a search which does not return any hit and
+// only tracks the total of hits up to options.Approximate
+func (c *Client) approximateCount(query interface{}, indexList []string, typeList []string, options CountOptions, args url.Values) (*CountResponse, error) {
+	body, err := queryWith(query, map[string]interface{}{
+		"size":             0,
+		"track_total_hits": options.Approximate,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if options.MinScore != 0 {
+		body["min_score"] = options.MinScore
+	}
+
+	r := Request{
+		Query:     body,
+		IndexList: indexList,
+		TypeList:  typeList,
+		Method:    "POST",
+		API:       "_search",
+		ExtraArgs: args,
+	}
+
+	var resp struct {
+		Hits            Hits  `json:"hits"`
+		TerminatedEarly bool  `json:"terminated_early"`
+		Shards          Shard `json:"_shards"`
+	}
+	if err := c.doInto(&r, &resp); err != nil {
+		return nil, err
+	}
+
+	count := &CountResponse{
+		Count:           resp.Hits.Total.Value,
+		Relation:        resp.Hits.Total.Relation,
+		TerminatedEarly: resp.TerminatedEarly,
+		Shards:          resp.Shards,
+	}
+	if count.TerminatedEarly {
+		count.Relation = TotalHitsGreaterOrEqual
+	}
+
+	return count, nil
+}
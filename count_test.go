@@ -0,0 +1,152 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	. "github.com/go-check/check"
+)
+
+func (s *GoesTestSuite) TestCountWithOptions(c *C) {
+	indexName := "testcountwithoptions"
+	docType := "tweet"
+
+	conn := NewClient(ESHost, ESPort)
+	if supported, _ := conn.versionAtLeast("7"); supported {
+		docType = "_doc"
+	}
+	conn.DeleteIndex(indexName)
+
+	_, err := conn.CreateIndex(indexName, map[string]interface{}{
+		"settings": map[string]interface{}{"index.number_of_shards": 1},
+	})
+	c.Assert(err, IsNil)
+	defer conn.DeleteIndex(indexName)
+
+	docs := []Document{}
+	for i := 0; i < 5; i++ {
+		docs = append(docs, Document{
+			Index:       indexName,
+			Type:        docType,
+			ID:          strconv.Itoa(i),
+			BulkCommand: BulkCommandIndex,
+			Fields:      map[string]interface{}{"user": "foo", "rank": i},
+		})
+	}
+	_, err = conn.BulkSend(docs)
+	c.Assert(err, IsNil)
+	_, err = conn.RefreshIndex(indexName)
+	c.Assert(err, IsNil)
+
+	resp, err := conn.CountWithOptions(nil, []string{indexName}, nil, CountOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(resp.Count, Equals, uint64(5))
+	c.Assert(resp.Exact(), Equals, true)
+
+	resp, err = conn.CountWithOptions(nil, []string{indexName}, nil, CountOptions{TerminateAfter: 2})
+	c.Assert(err, IsNil)
+	c.Assert(resp.Count, Equals, uint64(2))
+	c.Assert(resp.TerminatedEarly, Equals, true)
+	c.Assert(resp.Exact(), Equals, false)
+
+	resp, err = conn.CountWithOptions(nil, []string{indexName}, nil, CountOptions{QueryString: "rank:[3 TO *]"})
+	c.Assert(err, IsNil)
+	c.Assert(resp.Count, Equals, uint64(2))
+
+	resp, err = conn.CountWithOptions(nil, []string{indexName}, nil, CountOptions{Approximate: 3})
+	c.Assert(err, IsNil)
+	if supported, _ := conn.versionAtLeast("7"); supported {
+		c.Assert(resp.Count, Equals, uint64(3))
+		c.Assert(resp.Exact(), Equals, false)
+	} else {
+		c.Assert(resp.Count, Equals, uint64(5))
+		c.Assert(resp.Exact(), Equals, true)
+	}
+
+	resp, err = conn.CountWithOptions(nil, []string{indexName}, nil, CountOptions{Approximate: 10})
+	c.Assert(err, IsNil)
+	c.Assert(resp.Count, Equals, uint64(5))
+	c.Assert(resp.Exact(), Equals, true)
+}
+
+func (s *GoesTestSuite) TestCountWithOptionsRequest(c *C) {
+	var requests []string
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/" {
+			w.Write([]byte(`{"version": {"number": "7.10.0"}}`))
+			return
+		}
+
+		var body map[string]interface{}
+		decoder := json.NewDecoder(r.Body)
+		decoder.UseNumber()
+		c.Check(decoder.Decode(&body), IsNil)
+		data, _ := json.Marshal(body)
+		requests = append(requests, r.URL.RequestURI()+" "+string(data))
+
+		if r.URL.Path == "/a/_count" {
+			w.Write([]byte(`{"count": 4, "terminated_early": true, "_shards": {"total": 1, "successful": 1}}`))
+			return
+		}
+		w.Write([]byte(`{"hits": {"total": {"value": 100, "relation": "gte"}, "hits": []}}`))
+	})
+	defer ts.Close()
+
+	query := map[string]interface{}{"query": map[string]interface{}{"match_all": map[string]interface{}{}}}
+	extraArgs := url.Values{"preference": []string{"_local"}}
+
+	resp, err := conn.CountWithOptions(query, []string{"a"}, nil, CountOptions{
+		TerminateAfter: 4,
+		MinScore:       0.5,
+		Routing:        []string{"r1", "r2"},
+		ExtraArgs:      extraArgs,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(resp, DeepEquals, &CountResponse{
+		Count:           4,
+		Relation:        TotalHitsGreaterOrEqual,
+		TerminatedEarly: true,
+		Shards:          Shard{Total: 1, Successful: 1},
+	})
+
+	resp, err = conn.CountWithOptions(map[string]interface{}{
+		"query": map[string]interface{}{"term": map[string]interface{}{"id": uint64(9007199254740993)}},
+	}, []string{"a", "b"}, nil, CountOptions{MinScore: 0.5, Approximate: 100})
+	c.Assert(err, IsNil)
+	c.Assert(resp.Count, Equals, uint64(100))
+	c.Assert(resp.Exact(), Equals, false)
+
+	c.Assert(extraArgs, HasLen, 1)
+	c.Assert(requests, DeepEquals, []string{
+		`/a/_count?min_score=0.5&preference=_local&routing=r1%2Cr2&terminate_after=4 {"query":{"match_all":{}}}`,
+		`/a,b/_search {"min_score":0.5,"query":{"term":{"id":9007199254740993}},"size":0,"track_total_hits":100}`,
+	})
+}
+
+func (s *GoesTestSuite) TestApproximateCountBeforeES7(c *C) {
+	var paths []string
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/" {
+			w.Write([]byte(`{"version": {"number": "6.8.0"}}`))
+			return
+		}
+
+		paths = append(paths, r.URL.RequestURI())
+		w.Write([]byte(`{"count": 12}`))
+	})
+	defer ts.Close()
+
+	resp, err := conn.CountWithOptions(nil, []string{"a"}, nil, CountOptions{Approximate: 10})
+	c.Assert(err, IsNil)
+	c.Assert(resp.Count, Equals, uint64(12))
+	c.Assert(resp.Exact(), Equals, true)
+	c.Assert(paths, DeepEquals, []string{"/a/_count"})
+}
@@ -0,0 +1,138 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// Explanation details how a score was computed, Details holding the
+// explanations of the values it was computed from
+type Explanation struct {
+	Value       float64       `json:"value"`
+	Description string        `json:"description"`
+	Details     []Explanation `json:"details"`
+}
+
+// ExplainResponse holds the explanation of the score of a document for a
+// query
+type ExplainResponse struct {
+	Index       string       `json:"_index"`
+	Type        string       `json:"_type"`
+	ID          string       `json:"_id"`
+	Matched     bool         `json:"matched"`
+	Explanation *Explanation `json:"explanation"`
+}
+
+// QueryExplanation holds the explanation of a query validated on an index
+type QueryExplanation struct {
+	Index       string `json:"index"`
+	Shard       int    `json:"shard"`
+	Valid       bool   `json:"valid"`
+	Explanation string `json:"explanation"`
+	Error       string `json:"error"`
+}
+
+// ValidateResponse holds the result of a query validation. Error is only set
+// for an invalid query validated without explain.
+type ValidateResponse struct {
+	Valid        bool               `json:"valid"`
+	Shards       Shard              `json:"_shards"`
+	Explanations []QueryExplanation `json:"explanations"`
+	Error        string             `json:"error"`
+}
+
+// Profile holds the timings of a profiled search, per shard
+type Profile struct {
+	Shards []ShardProfile `json:"shards"`
+}
+
+// ShardProfile holds the timings of a profiled search on a shard. ID holds
+// the node id, index name and shard number.
+type ShardProfile struct {
+	ID           string          `json:"id"`
+	Searches     []SearchProfile `json:"searches"`
+	Aggregations []QueryProfile  `json:"aggregations"`
+}
+
+// SearchProfile holds the timings of the queries, query rewriting and
+// collectors of a search on a shard
+type SearchProfile struct {
+	Query       []QueryProfile     `json:"query"`
+	RewriteTime int64              `json:"rewrite_time"`
+	Collector   []CollectorProfile `json:"collector"`
+}
+
+// QueryProfile holds the timings of a query clause or an aggregation and of
+// its children. Breakdown holds the time spent in each low-level step, in
+// nanoseconds, and how many times it ran.
+type QueryProfile struct {
+	Type        string           `json:"type"`
+	Description string           `json:"description"`
+	TimeInNanos int64            `json:"time_in_nanos"`
+	Breakdown   map[string]int64 `json:"breakdown"`
+	Children    []QueryProfile   `json:"children"`
+}
+
+// CollectorProfile holds the timings of a collector and of the collectors it
+// wraps
+type CollectorProfile struct {
+	Name        string             `json:"name"`
+	Reason      string             `json:"reason"`
+	TimeInNanos int64              `json:"time_in_nanos"`
+	Children    []CollectorProfile `json:"children"`
+}
+
+// Explain computes the score of a document for a query and explains it.
+// documentType may be empty since ES 7.
+func (c *Client) Explain(index string, documentType string, id string, query interface{}) (*ExplainResponse, error) {
+	r := Request{
+		Query:     query,
+		IndexList: []string{index},
+		Method:    "POST",
+		API:       "_explain/" + id,
+	}
+	if documentType != "" {
+		r.TypeList = []string{documentType}
+		r.ID = id
+		r.API = "_explain"
+	}
+
+	resp := &ExplainResponse{}
+	return resp, c.doInto(&r, resp)
+}
+
+// ValidateQuery checks whether query is valid on the indexes without running
+// it. With explain, each index tells why the query is invalid or how it was
+// parsed. With rewrite, the explanations hold the query as rewritten for
+// execution.
+func (c *Client) ValidateQuery(query interface{}, indexList []string, explain bool, rewrite bool) (*ValidateResponse, error) {
+	r := Request{
+		Query:     query,
+		IndexList: indexList,
+		Method:    "POST",
+		API:       "_validate/query",
+		ExtraArgs: url.Values{
+			"explain": []string{strconv.FormatBool(explain)},
+			"rewrite": []string{strconv.FormatBool(rewrite)},
+		},
+	}
+
+	resp := &ValidateResponse{}
+	return resp, c.doInto(&r, resp)
+}
+
+// ProfileSearch runs a search like Search, with profiling enabled. The
+// timings of the query clauses and collectors are returned in the Profile
+// of the response.
+func (c *Client) ProfileSearch(query interface{}, indexList []string, typeList []string, extraArgs url.Values) (*Response, error) {
+	body, err := queryWith(query, map[string]interface{}{"profile": true})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Search(body, indexList, typeList, extraArgs)
+}
@@ -0,0 +1,183 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	. "github.com/go-check/check"
+)
+
+func (s *GoesTestSuite) TestExplainValidateProfile(c *C) {
+	indexName := "testexplain"
+	docType := "tweet"
+
+	conn := NewClient(ESHost, ESPort)
+	if supported, _ := conn.versionAtLeast("5"); !supported {
+		c.Skip("Profiling requires ES 5, skipping this test")
+	}
+	if supported, _ := conn.versionAtLeast("7"); supported {
+		docType = ""
+	}
+	conn.DeleteIndex(indexName)
+
+	_, err := conn.CreateIndex(indexName, map[string]interface{}{
+		"settings": map[string]interface{}{"index.number_of_shards": 1},
+	})
+	c.Assert(err, IsNil)
+	defer conn.DeleteIndex(indexName)
+
+	indexType := docType
+	if indexType == "" {
+		indexType = "_doc"
+	}
+	_, err = conn.Index(Document{
+		Index:  indexName,
+		Type:   indexType,
+		ID:     "1",
+		Fields: map[string]interface{}{"user": "foo bar"},
+	}, url.Values{"refresh": []string{"true"}})
+	c.Assert(err, IsNil)
+
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"match": map[string]interface{}{"user": "foo"},
+		},
+	}
+
+	explained, err := conn.Explain(indexName, docType, "1", query)
+	c.Assert(err, IsNil)
+	c.Assert(explained.ID, Equals, "1")
+	c.Assert(explained.Matched, Equals, true)
+	c.Assert(explained.Explanation.Value > 0, Equals, true)
+	c.Assert(explained.Explanation.Description, Not(Equals), "")
+
+	explained, err = conn.Explain(indexName, docType, "1", map[string]interface{}{
+		"query": map[string]interface{}{
+			"match": map[string]interface{}{"user": "baz"},
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(explained.Matched, Equals, false)
+
+	valid, err := conn.ValidateQuery(query, []string{indexName}, true, false)
+	c.Assert(err, IsNil)
+	c.Assert(valid.Valid, Equals, true)
+	c.Assert(valid.Explanations, HasLen, 1)
+	c.Assert(valid.Explanations[0].Index, Equals, indexName)
+	c.Assert(valid.Explanations[0].Explanation, Equals, "user:foo")
+
+	valid, err = conn.ValidateQuery(map[string]interface{}{
+		"query": map[string]interface{}{"unknown_query": map[string]interface{}{}},
+	}, []string{indexName}, true, false)
+	c.Assert(err, IsNil)
+	c.Assert(valid.Valid, Equals, false)
+	c.Assert(valid.Explanations[0].Error, Not(Equals), "")
+
+	resp, err := conn.ProfileSearch(query, []string{indexName}, nil, url.Values{})
+	c.Assert(err, IsNil)
+	c.Assert(resp.Hits.Hits, HasLen, 1)
+	c.Assert(resp.Profile.Shards, HasLen, 1)
+
+	search := resp.Profile.Shards[0].Searches[0]
+	c.Assert(search.Query, HasLen, 1)
+	c.Assert(search.Query[0].Type, Equals, "TermQuery")
+	c.Assert(search.Query[0].Description, Equals, "user:foo")
+	c.Assert(search.Query[0].TimeInNanos > 0, Equals, true)
+	c.Assert(search.Collector, Not(HasLen), 0)
+
+	resp, err = conn.Search(query, []string{indexName}, nil, url.Values{})
+	c.Assert(err, IsNil)
+	c.Assert(resp.Profile, IsNil)
+}
+
+func (s *GoesTestSuite) TestExplainRequest(c *C) {
+	var paths []string
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.RequestURI())
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"matched": true, "explanation": {
+			"value": 1.5, "description": "sum of:",
+			"details": [{"value": 1.5, "description": "weight(user:foo)", "details": []}]
+		}}`))
+	})
+	defer ts.Close()
+
+	explained, err := conn.Explain("a", "", "1", nil)
+	c.Assert(err, IsNil)
+	c.Assert(explained.Explanation, DeepEquals, &Explanation{
+		Value:       1.5,
+		Description: "sum of:",
+		Details:     []Explanation{{Value: 1.5, Description: "weight(user:foo)", Details: []Explanation{}}},
+	})
+
+	_, err = conn.Explain("a", "tweet", "1", nil)
+	c.Assert(err, IsNil)
+
+	_, err = conn.ValidateQuery(nil, []string{"a", "b"}, false, true)
+	c.Assert(err, IsNil)
+
+	c.Assert(paths, DeepEquals, []string{
+		"/a/_explain/1",
+		"/a/tweet/1/_explain",
+		"/a,b/_validate/query?explain=false&rewrite=true",
+	})
+}
+
+func (s *GoesTestSuite) TestProfileSearchRequest(c *C) {
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		decoder := json.NewDecoder(r.Body)
+		decoder.UseNumber()
+		c.Check(decoder.Decode(&body), IsNil)
+		// Integers above 2^53 are not rounded to a float64
+		c.Check(body, DeepEquals, map[string]interface{}{
+			"size":         json.Number("1"),
+			"search_after": []interface{}{json.Number("9007199254740993")},
+			"profile":      true,
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"hits": {"total": 0, "hits": []}, "profile": {"shards": [{
+			"id": "[node][a][0]",
+			"searches": [{
+				"query": [{
+					"type": "BooleanQuery", "description": "+user:foo +views:[1 TO 5]", "time_in_nanos": 300,
+					"breakdown": {"score": 10, "score_count": 1},
+					"children": [
+						{"type": "TermQuery", "description": "user:foo", "time_in_nanos": 100},
+						{"type": "IndexOrDocValuesQuery", "description": "views:[1 TO 5]", "time_in_nanos": 180}
+					]
+				}],
+				"rewrite_time": 42,
+				"collector": [{"name": "SimpleTopScoreDocCollector", "reason": "search_top_hits", "time_in_nanos": 25}]
+			}],
+			"aggregations": []
+		}]}}`))
+	})
+	defer ts.Close()
+
+	resp, err := conn.ProfileSearch(map[string]interface{}{
+		"size":         1,
+		"search_after": []uint64{9007199254740993},
+	}, []string{"a"}, nil, url.Values{})
+	c.Assert(err, IsNil)
+
+	shard := resp.Profile.Shards[0]
+	c.Assert(shard.ID, Equals, "[node][a][0]")
+	c.Assert(shard.Searches[0].RewriteTime, Equals, int64(42))
+
+	query := shard.Searches[0].Query[0]
+	c.Assert(query.Type, Equals, "BooleanQuery")
+	c.Assert(query.Breakdown, DeepEquals, map[string]int64{"score": 10, "score_count": 1})
+	c.Assert(query.Children, HasLen, 2)
+	c.Assert(query.Children[1].Description, Equals, "views:[1 TO 5]")
+	c.Assert(query.Children[1].TimeInNanos, Equals, int64(180))
+	c.Assert(shard.Searches[0].Collector, DeepEquals, []CollectorProfile{
+		{Name: "SimpleTopScoreDocCollector", Reason: "search_top_hits", TimeInNanos: 25},
+	})
+}
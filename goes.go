@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"mime"
 	"net/http"
 	"net/url"
 	"reflect"
@@ -21,14 +22,43 @@ import (
 const (
 	// BulkCommandIndex specifies a bulk doc should be indexed
 	BulkCommandIndex = "index"
+	// BulkCommandCreate specifies a bulk doc should be indexed, failing if
+	// it already exists
+	BulkCommandCreate = "create"
+	// BulkCommandUpdate specifies a bulk doc should be partially updated, its
+	// Fields holding the body of an update
+	BulkCommandUpdate = "update"
 	// BulkCommandDelete specifies a bulk doc should be deleted
 	BulkCommandDelete = "delete"
+
+	// AliasActionAdd adds an alias to an index
+	AliasActionAdd = "add"
+	// AliasActionRemove removes an alias from an index
+	AliasActionRemove = "remove"
+	// AliasActionRemoveIndex deletes an index, atomically with the other actions
+	AliasActionRemoveIndex = "remove_index"
+
+	// TotalHitsEqual is the relation of an exact total of hits
+	TotalHitsEqual = "eq"
+	// TotalHitsGreaterOrEqual is the relation of a total of hits which is a
+	// lower bound
+	TotalHitsGreaterOrEqual = "gte"
 )
 
 func (err *SearchError) Error() string {
 	return fmt.Sprintf("[%d] %s", err.StatusCode, err.Msg)
 }
 
+func (err *HTTPError) Error() string {
+	if err.Location != "" {
+		return fmt.Sprintf("[%d] redirected to %s", err.StatusCode, err.Location)
+	}
+	if len(err.Body) == 0 {
+		return fmt.Sprintf("[%d] %s", err.StatusCode, http.StatusText(int(err.StatusCode)))
+	}
+	return fmt.Sprintf("[%d] %s", err.StatusCode, err.Body)
+}
+
 // NewClient initiates a new client for an elasticsearch server
 //
 // This function is pretty useless for now but might be useful in a near future
@@ -37,7 +67,33 @@ func NewClient(host string, port string) *Client {
 	return &Client{host, port, http.DefaultClient, ""}
 }
 
+// checkRedirect is the redirect policy of http clients which do not have
+// their own. It only follows redirects which keep the request as it is: the
+// http.Client turns a 301, 302 or 303 into a GET without body, so these are
+// rejected for requests which are not GET or HEAD or which have a body, and
+// reported as an HTTPError. 307 and 308 replay the method and the body.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+
+	first := via[0]
+	if (first.Method == "GET" || first.Method == "HEAD") && first.ContentLength == 0 {
+		return nil
+	}
+	if req.Response != nil {
+		switch req.Response.StatusCode {
+		case http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+			return nil
+		}
+	}
+
+	return http.ErrUseLastResponse
+}
+
 // WithHTTPClient sets the http.Client to be used with the connection. Returns the original client.
+// Unless cl sets its own CheckRedirect, redirects which would turn a request into a GET
+// without its body are not followed and are returned as an HTTPError.
 func (c *Client) WithHTTPClient(cl *http.Client) *Client {
 	c.Client = cl
 	return c
@@ -65,6 +121,45 @@ func (c *Client) Version() (string, error) {
 	return "", errors.New("No version returned by ElasticSearch Server")
 }
 
+// versionAtLeast tells whether the connected ES server is at least version min
+func (c *Client) versionAtLeast(min string) (bool, error) {
+	version, err := c.Version()
+	if err != nil {
+		return false, err
+	}
+	return compareVersions(version, min) >= 0, nil
+}
+
+// compareVersions compares two dotted version numbers part by part, so that
+// 7.10 is greater than 7.9, and returns -1, 0 or 1 like strings.Compare
+func compareVersions(a string, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		x, y := versionPart(as, i), versionPart(bs, i)
+		if x < y {
+			return -1
+		}
+		if x > y {
+			return 1
+		}
+	}
+	return 0
+}
+
+// versionPart returns the numeric value of the i-th part of a version,
+// ignoring suffixes such as -SNAPSHOT
+func versionPart(parts []string, i int) int {
+	if i >= len(parts) {
+		return 0
+	}
+	part := parts[i]
+	if end := strings.IndexFunc(part, func(r rune) bool { return r < '0' || r > '9' }); end >= 0 {
+		part = part[:end]
+	}
+	n, _ := strconv.Atoi(part)
+	return n
+}
+
 // CreateIndex creates a new index represented by a name and a mapping
 func (c *Client) CreateIndex(name string, mapping interface{}) (*Response, error) {
 	r := Request{
@@ -155,7 +250,9 @@ func (c *Client) IndexStatus(indexList []string) (*Response, error) {
 	return c.Do(&r)
 }
 
-// BulkSend bulk adds multiple documents in bulk mode
+// BulkSend bulk adds multiple documents in bulk mode. The Pipeline of a
+// document is only used by the index and create commands, ErrUpdatePipeline
+// is returned for an update command with a Pipeline.
 func (c *Client) BulkSend(documents []Document) (*Response, error) {
 	// We do not generate a traditional JSON here (often a one liner)
 	// Elasticsearch expects one line of JSON per line (EOL = \n)
@@ -179,12 +276,26 @@ func (c *Client) BulkSend(documents []Document) (*Response, error) {
 	i := 0
 
 	for _, doc := range documents {
+		meta := map[string]interface{}{
+			"_index": doc.Index,
+			"_type":  doc.Type,
+			"_id":    doc.ID,
+		}
+		if doc.IfPrimaryTerm != 0 {
+			meta["if_seq_no"] = doc.IfSeqNo
+			meta["if_primary_term"] = doc.IfPrimaryTerm
+		}
+		if doc.Pipeline != "" {
+			switch doc.BulkCommand {
+			case BulkCommandIndex, BulkCommandCreate:
+				meta["pipeline"] = doc.Pipeline
+			case BulkCommandUpdate:
+				return nil, ErrUpdatePipeline
+			}
+		}
+
 		action, err := json.Marshal(map[string]interface{}{
-			doc.BulkCommand: map[string]interface{}{
-				"_index": doc.Index,
-				"_type":  doc.Type,
-				"_id":    doc.ID,
-			},
+			doc.BulkCommand: meta,
 		})
 
 		if err != nil {
@@ -264,7 +375,66 @@ func (c *Client) Search(query interface{}, indexList []string, typeList []string
 	return c.Do(&r)
 }
 
-// Count executes a count query against an index, use the Count field in the response for the result
+// MSearch executes multiple search queries in a single request. indexList
+// and typeList are used for queries whose header does not define them.
+// The responses are returned in the order of the queries, each of them
+// carrying its own error so a failing query does not hide the other ones.
+func (c *Client) MSearch(queries []MSearchQuery, indexList []string, typeList []string, extraArgs url.Values) ([]Response, error) {
+	// Like _bulk, _msearch expects one line of JSON per line: the header
+	// then the query of each search, plus an extra \n at the very end.
+	//
+	// len(queries) * 2 : header + query
+	// + 1 : room for the trailing \n
+	data := make([][]byte, 0, len(queries)*2+1)
+
+	for _, q := range queries {
+		header := q.Header
+		if header == nil {
+			header = map[string]interface{}{}
+		}
+		h, err := json.Marshal(header)
+		if err != nil {
+			return nil, err
+		}
+
+		query := q.Query
+		if query == nil {
+			query = map[string]interface{}{}
+		}
+		b, err := json.Marshal(query)
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, h, b)
+	}
+
+	// forces an extra trailing \n absolutely necessary for elasticsearch
+	data = append(data, []byte(nil))
+
+	r := Request{
+		IndexList: indexList,
+		TypeList:  typeList,
+		Method:    "POST",
+		API:       "_msearch",
+		Body:      bytes.Join(data, []byte("\n")),
+		ExtraArgs: extraArgs,
+	}
+
+	resp, err := c.Do(&r)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range resp.Responses {
+		resp.Responses[i].setError()
+	}
+
+	return resp.Responses, nil
+}
+
+// Count executes a count query against an index, use the Count field in the response for the result.
+// CountWithOptions takes typed parameters and can count approximately.
 func (c *Client) Count(query interface{}, indexList []string, typeList []string, extraArgs url.Values) (*Response, error) {
 	r := Request{
 		Query:     query,
@@ -322,6 +492,40 @@ func (c *Client) DeleteByQuery(query interface{}, indexList []string, typeList [
 	return c.Do(&r)
 }
 
+// UpdateByQuery updates the documents matching the query, optionally running
+// a script on each of them. Set wait_for_completion=false in extraArgs to run
+// it in the background, Task then holds the id of the task to track with
+// GetTask or WaitForTask.
+func (c *Client) UpdateByQuery(query interface{}, indexList []string, typeList []string, extraArgs url.Values) (*BulkByScrollResponse, error) {
+	r := Request{
+		Query:     query,
+		IndexList: indexList,
+		TypeList:  typeList,
+		Method:    "POST",
+		API:       "_update_by_query",
+		ExtraArgs: extraArgs,
+	}
+
+	resp := &BulkByScrollResponse{}
+	return resp, c.doInto(&r, resp)
+}
+
+// Reindex copies documents from one index, local or remote, to another as
+// described by body, which is usually a ReindexBody. Set
+// wait_for_completion=false in extraArgs to run it in the background, Task
+// then holds the id of the task to track with GetTask or WaitForTask.
+func (c *Client) Reindex(body interface{}, extraArgs url.Values) (*BulkByScrollResponse, error) {
+	r := Request{
+		Query:     body,
+		Method:    "POST",
+		API:       "_reindex",
+		ExtraArgs: extraArgs,
+	}
+
+	resp := &BulkByScrollResponse{}
+	return resp, c.doInto(&r, resp)
+}
+
 // Scan starts scroll over an index.
 // For ES versions < 5.x, it uses search_type=scan; for 5.x it uses sort=_doc. This means that data
 // will  be returned in the initial response for 5.x versions, but not for older versions. Code
@@ -389,15 +593,63 @@ func (c *Client) Get(index string, documentType string, id string, extraArgs url
 	return c.Do(&r)
 }
 
+// MGet fetches multiple documents in a single request. Each document may
+// target its own index and type, set its own routing and filter its _source.
+// The documents are returned in Response.Docs, in the order they were
+// requested, each of them decoded as it would be by Get.
+func (c *Client) MGet(docs []MGetDoc, extraArgs url.Values) (*Response, error) {
+	r := Request{
+		Query:     map[string]interface{}{"docs": docs},
+		Method:    "POST",
+		API:       "_mget",
+		ExtraArgs: extraArgs,
+	}
+
+	return c.mget(&r)
+}
+
+// MGetIDs fetches multiple documents of an index by their ids. The documents
+// are returned in Response.Docs, in the order of ids.
+func (c *Client) MGetIDs(index string, documentType string, ids []string, extraArgs url.Values) (*Response, error) {
+	r := Request{
+		Query:     map[string]interface{}{"ids": ids},
+		IndexList: []string{index},
+		Method:    "POST",
+		API:       "_mget",
+		ExtraArgs: extraArgs,
+	}
+
+	if documentType != "" {
+		r.TypeList = []string{documentType}
+	}
+
+	return c.mget(&r)
+}
+
+func (c *Client) mget(r *Request) (*Response, error) {
+	resp, err := c.Do(r)
+	if err != nil {
+		return resp, err
+	}
+
+	for i := range resp.Docs {
+		resp.Docs[i].setError()
+	}
+
+	return resp, nil
+}
+
 // Index indexes a Document
 // The extraArgs is a list of url.Values that you can send to elasticsearch as
 // URL arguments, for example, to control routing, ttl, version, op_type, etc.
+// The document is only written if it was not modified since IfSeqNo and
+// IfPrimaryTerm when they are set, and goes through its Pipeline if any.
 func (c *Client) Index(d Document, extraArgs url.Values) (*Response, error) {
 	r := Request{
 		Query:     d.Fields,
 		IndexList: []string{d.Index.(string)},
 		TypeList:  []string{d.Type},
-		ExtraArgs: extraArgs,
+		ExtraArgs: pipelineArgs(d, concurrencyArgs(d, extraArgs)),
 		Method:    "POST",
 	}
 
@@ -416,7 +668,7 @@ func (c *Client) Delete(d Document, extraArgs url.Values) (*Response, error) {
 	r := Request{
 		IndexList: []string{d.Index.(string)},
 		TypeList:  []string{d.Type},
-		ExtraArgs: extraArgs,
+		ExtraArgs: concurrencyArgs(d, extraArgs),
 		Method:    "DELETE",
 		ID:        d.ID.(string),
 	}
@@ -424,6 +676,209 @@ func (c *Client) Delete(d Document, extraArgs url.Values) (*Response, error) {
 	return c.Do(&r)
 }
 
+// ReadModifyWrite gets a document, passes its _source to modify and indexes
+// the returned fields only if the document was not modified in the meantime.
+// On a version conflict the whole cycle is retried, up to retries times.
+// modify receives a nil source if the document does not exist yet, in which
+// case it is created.
+func (c *Client) ReadModifyWrite(index string, documentType string, id string, retries int, modify func(source map[string]interface{}) (interface{}, error)) (*Response, error) {
+	for attempt := 0; ; attempt++ {
+		current, err := c.Get(index, documentType, id, url.Values{})
+		if err != nil && current.Status != 404 {
+			return current, err
+		}
+
+		fields, err := modify(current.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		d := Document{
+			Index:  index,
+			Type:   documentType,
+			ID:     id,
+			Fields: fields,
+		}
+		args := url.Values{}
+
+		switch {
+		case !current.Found:
+			args.Set("op_type", "create")
+		case current.PrimaryTerm != 0:
+			d.IfSeqNo = current.SeqNo
+			d.IfPrimaryTerm = current.PrimaryTerm
+		default:
+			// _seq_no is not available before ES 6.7
+			args.Set("version", strconv.Itoa(current.Version))
+		}
+
+		resp, err := c.Index(d, args)
+		if IsConflict(err) && attempt < retries {
+			continue
+		}
+
+		return resp, err
+	}
+}
+
+// IsConflict tells whether err is a version conflict reported by elasticsearch
+func IsConflict(err error) bool {
+	if searchErr, ok := err.(*SearchError); ok {
+		return searchErr.StatusCode == http.StatusConflict
+	}
+	return false
+}
+
+// copyArgs returns a copy of extraArgs with room for extra more parameters,
+// so that they can be added without modifying extraArgs itself
+func copyArgs(extraArgs url.Values, extra int) url.Values {
+	args := make(url.Values, len(extraArgs)+extra)
+	for k, v := range extraArgs {
+		args[k] = v
+	}
+
+	return args
+}
+
+// queryWith returns query as a map with the extra top-level parameters set.
+// Numbers are kept as json.Number, so that integers which do not fit in a
+// float64, e.g. in term queries or search_after values, are sent unchanged.
+func queryWith(query interface{}, extra map[string]interface{}) (map[string]interface{}, error) {
+	body := map[string]interface{}{}
+	if query != nil {
+		data, err := json.Marshal(query)
+		if err != nil {
+			return nil, err
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&body); err != nil {
+			return nil, err
+		}
+	}
+	for name, value := range extra {
+		body[name] = value
+	}
+
+	return body, nil
+}
+
+// concurrencyArgs returns a copy of extraArgs with the optimistic concurrency
+// control parameters of d added
+func concurrencyArgs(d Document, extraArgs url.Values) url.Values {
+	if d.IfPrimaryTerm == 0 {
+		return extraArgs
+	}
+
+	args := copyArgs(extraArgs, 2)
+	args.Set("if_seq_no", strconv.FormatInt(d.IfSeqNo, 10))
+	args.Set("if_primary_term", strconv.FormatInt(d.IfPrimaryTerm, 10))
+
+	return args
+}
+
+// pipelineArgs returns a copy of extraArgs with the ingest pipeline of d
+// added
+func pipelineArgs(d Document, extraArgs url.Values) url.Values {
+	if d.Pipeline == "" {
+		return extraArgs
+	}
+
+	args := copyArgs(extraArgs, 1)
+	args.Set("pipeline", d.Pipeline)
+
+	return args
+}
+
+// TrackTotalHits returns a copy of extraArgs with track_total_hits set. Hits
+// are counted exactly up to limit, after which Hits.Total is a lower bound.
+// A negative limit counts all hits exactly, and 0 does not count them at
+// all. Limits require ES 7, and track_total_hits itself ES 6.
+func TrackTotalHits(extraArgs url.Values, limit int) url.Values {
+	args := copyArgs(extraArgs, 1)
+
+	switch {
+	case limit < 0:
+		args.Set("track_total_hits", "true")
+	case limit == 0:
+		args.Set("track_total_hits", "false")
+	default:
+		args.Set("track_total_hits", strconv.Itoa(limit))
+	}
+
+	return args
+}
+
+// UnmarshalJSON decodes the total of hits both as a number, as reported
+// before ES 7, and as an object with its relation. Before ES 7, hits which
+// are not counted have a total of -1.
+func (t *TotalHits) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] != '{' {
+		var total int64
+		if err := json.Unmarshal(data, &total); err != nil {
+			return err
+		}
+		if total >= 0 {
+			t.Value = uint64(total)
+			t.Relation = TotalHitsEqual
+		}
+		return nil
+	}
+
+	type totalHits TotalHits
+	return json.Unmarshal(data, (*totalHits)(t))
+}
+
+// Exact returns whether the total is the exact number of hits, rather than a
+// lower bound
+func (t TotalHits) Exact() bool {
+	return t.Relation == TotalHitsEqual
+}
+
+// UnmarshalJSON decodes retries both as reported by ES 2.x and later versions
+func (r *Retries) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] != '{' {
+		return json.Unmarshal(data, &r.Bulk)
+	}
+
+	type retries Retries
+	return json.Unmarshal(data, (*retries)(r))
+}
+
+// UnmarshalJSON decodes a hit, unwrapping the hits of each of its inner hits.
+// Numeric sort values are decoded as json.Number, so that they can be sent
+// back unchanged in a search_after.
+func (h *Hit) UnmarshalJSON(data []byte) error {
+	type hit Hit
+	var raw struct {
+		hit
+		Sort      json.RawMessage `json:"sort"`
+		InnerHits map[string]struct {
+			Hits Hits `json:"hits"`
+		} `json:"inner_hits"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*h = Hit(raw.hit)
+	if len(raw.Sort) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(raw.Sort))
+		decoder.UseNumber()
+		if err := decoder.Decode(&h.Sort); err != nil {
+			return err
+		}
+	}
+	if raw.InnerHits != nil {
+		h.InnerHits = make(map[string]Hits, len(raw.InnerHits))
+		for name, inner := range raw.InnerHits {
+			h.InnerHits[name] = inner.Hits
+		}
+	}
+
+	return nil
+}
+
 // Buckets returns list of buckets in aggregation
 func (a Aggregation) Buckets() []Bucket {
 	result := []Bucket{}
@@ -454,14 +909,18 @@ func (b Bucket) Aggregation(name string) Aggregation {
 	return Aggregation{}
 }
 
-// PutMapping registers a specific mapping for one or more types in one or more indexes
+// PutMapping registers a specific mapping for one or more types in one or more indexes.
+// typeName may be empty for the typeless mappings of ES 7 and later.
 func (c *Client) PutMapping(typeName string, mapping interface{}, indexes []string) (*Response, error) {
 
 	r := Request{
 		Query:     mapping,
 		IndexList: indexes,
 		Method:    "PUT",
-		API:       "_mappings/" + typeName,
+		API:       "_mappings",
+	}
+	if typeName != "" {
+		r.API += "/" + typeName
 	}
 
 	return c.Do(&r)
@@ -473,7 +932,7 @@ func (c *Client) GetMapping(types []string, indexes []string) (*Response, error)
 	r := Request{
 		IndexList: indexes,
 		Method:    "GET",
-		API:       "_mapping/" + strings.Join(types, ","),
+		API:       namedAPI("_mapping", types),
 	}
 
 	return c.Do(&r)
@@ -492,13 +951,23 @@ func (c *Client) IndicesExist(indexes []string) (bool, error) {
 	return resp.Status == 200, err
 }
 
-// Update updates the specified document using the _update endpoint
+// Update updates the specified document using the _update endpoint.
+// Elasticsearch does not run ingest pipelines on updates, so when d has a
+// Pipeline the updated document is then run through it with an
+// _update_by_query of this document only. The update is made visible to
+// search first, and is kept if the pipeline fails.
 func (c *Client) Update(d Document, query interface{}, extraArgs url.Values) (*Response, error) {
+	args := concurrencyArgs(d, extraArgs)
+	if d.Pipeline != "" && args.Get("refresh") != "true" {
+		args = copyArgs(args, 1)
+		args.Set("refresh", "wait_for")
+	}
+
 	r := Request{
 		Query:     query,
 		IndexList: []string{d.Index.(string)},
 		TypeList:  []string{d.Type},
-		ExtraArgs: extraArgs,
+		ExtraArgs: args,
 		Method:    "POST",
 		API:       "_update",
 	}
@@ -507,7 +976,43 @@ func (c *Client) Update(d Document, query interface{}, extraArgs url.Values) (*R
 		r.ID = d.ID.(string)
 	}
 
-	return c.Do(&r)
+	resp, err := c.Do(&r)
+	if err != nil || d.Pipeline == "" {
+		return resp, err
+	}
+
+	return resp, c.updatePipeline(d, extraArgs)
+}
+
+// updatePipeline runs an updated document through its pipeline, routing it
+// and refreshing the index as its update. _update_by_query does not support
+// refresh=wait_for, it refreshes the index instead.
+func (c *Client) updatePipeline(d Document, extraArgs url.Values) error {
+	args := url.Values{"pipeline": []string{d.Pipeline}}
+	if routing := extraArgs.Get("routing"); routing != "" {
+		args.Set("routing", routing)
+	}
+	if refresh := extraArgs.Get("refresh"); refresh == "true" || refresh == "wait_for" {
+		args.Set("refresh", "true")
+	}
+
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"ids": map[string]interface{}{"values": []interface{}{d.ID}},
+		},
+	}
+	resp, err := c.UpdateByQuery(query, []string{d.Index.(string)}, []string{d.Type}, args)
+	if err != nil {
+		return err
+	}
+	if len(resp.Failures) > 0 {
+		return fmt.Errorf("Pipeline %s failed for document %v: %s", d.Pipeline, d.ID, resp.Failures[0])
+	}
+	if resp.Updated == 0 {
+		return fmt.Errorf("Pipeline %s did not run on document %v", d.Pipeline, d.ID)
+	}
+
+	return nil
 }
 
 // DeleteMapping deletes a mapping along with all data in the type
@@ -528,21 +1033,26 @@ func (c *Client) DeleteMapping(typeName string, indexes []string) (*Response, er
 }
 
 func (c *Client) modifyAlias(action string, alias string, indexes []string) (*Response, error) {
-	command := map[string]interface{}{
-		"actions": make([]map[string]interface{}, 0, 1),
+	actions := make([]AliasAction, 0, len(indexes))
+	for _, index := range indexes {
+		actions = append(actions, AliasAction{Action: action, Index: index, Alias: alias})
 	}
 
-	for _, index := range indexes {
-		command["actions"] = append(command["actions"].([]map[string]interface{}), map[string]interface{}{
-			action: map[string]interface{}{
-				"index": index,
-				"alias": alias,
-			},
+	return c.UpdateAliases(actions)
+}
+
+// UpdateAliases runs several alias actions (add, remove, remove_index) in a
+// single request, they are applied atomically by elasticsearch
+func (c *Client) UpdateAliases(actions []AliasAction) (*Response, error) {
+	command := make([]map[string]interface{}, 0, len(actions))
+	for _, action := range actions {
+		command = append(command, map[string]interface{}{
+			action.Action: action,
 		})
 	}
 
 	r := Request{
-		Query:  command,
+		Query:  map[string]interface{}{"actions": command},
 		Method: "POST",
 		API:    "_aliases",
 	}
@@ -552,12 +1062,12 @@ func (c *Client) modifyAlias(action string, alias string, indexes []string) (*Re
 
 // AddAlias creates an alias to one or more indexes
 func (c *Client) AddAlias(alias string, indexes []string) (*Response, error) {
-	return c.modifyAlias("add", alias, indexes)
+	return c.modifyAlias(AliasActionAdd, alias, indexes)
 }
 
 // RemoveAlias removes an alias to one or more indexes
 func (c *Client) RemoveAlias(alias string, indexes []string) (*Response, error) {
-	return c.modifyAlias("remove", alias, indexes)
+	return c.modifyAlias(AliasActionRemove, alias, indexes)
 }
 
 // AliasExists checks whether alias is defined on the server
@@ -614,12 +1124,7 @@ func (c *Client) Do(r Requester) (*Response, error) {
 		}
 	}
 
-	if len(esResp.RawError) > 0 && esResp.RawError[0] == '"' {
-		json.Unmarshal(esResp.RawError, &esResp.Error)
-	} else {
-		esResp.Error = string(esResp.RawError)
-	}
-	esResp.RawError = nil
+	esResp.setError()
 
 	if esResp.Error != "" {
 		return esResp, &SearchError{esResp.Error, esResp.Status}
@@ -628,8 +1133,57 @@ func (c *Client) Do(r Requester) (*Response, error) {
 	return esResp, nil
 }
 
+// doInto runs the request returned by the requestor and decodes the response
+// body into v. Unlike Do, which decodes every response as a Response, this
+// allows APIs to return their own types.
+func (c *Client) doInto(r Requester, v interface{}) error {
+	body, statusCode, err := c.DoRaw(r)
+	if err != nil {
+		return err
+	}
+
+	if statusCode >= 400 {
+		// Some APIs describe their failure in their own format, e.g. a timed
+		// out _cluster/health, so v is filled on a best effort basis
+		json.Unmarshal(body, v)
+
+		esResp := &Response{}
+		if err := json.Unmarshal(body, esResp); err == nil {
+			esResp.setError()
+		}
+		if esResp.Error == "" {
+			esResp.Error = http.StatusText(int(statusCode))
+		}
+		return &SearchError{esResp.Error, statusCode}
+	}
+
+	if len(body) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+// setError fills Error from the raw error returned by elasticsearch, which is
+// a string up to ES 1.x and an object afterwards
+func (r *Response) setError() {
+	if len(r.RawError) > 0 && r.RawError[0] == '"' {
+		json.Unmarshal(r.RawError, &r.Error)
+	} else {
+		r.Error = string(r.RawError)
+	}
+	r.RawError = nil
+}
+
 func (c *Client) doRequest(req *http.Request) ([]byte, uint64, error) {
-	resp, err := c.Client.Do(req)
+	client := c.Client
+	if client.CheckRedirect == nil {
+		withPolicy := *client
+		withPolicy.CheckRedirect = checkRedirect
+		client = &withPolicy
+	}
+
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -640,9 +1194,47 @@ func (c *Client) doRequest(req *http.Request) ([]byte, uint64, error) {
 		return nil, uint64(resp.StatusCode), err
 	}
 
-	if resp.StatusCode > 201 && resp.StatusCode < 400 {
-		return nil, uint64(resp.StatusCode), errors.New(string(body))
+	statusCode := uint64(resp.StatusCode)
+	contentType := resp.Header.Get("Content-Type")
+
+	switch {
+	case statusCode >= 200 && statusCode < 300:
+		// 202 is returned when a task was started in the background
+		return body, statusCode, nil
+	case statusCode >= 300 && statusCode < 400:
+		// Redirects are followed by the http.Client according to its
+		// CheckRedirect policy, anything reaching us has been rejected
+		return body, statusCode, &HTTPError{
+			StatusCode:  statusCode,
+			ContentType: contentType,
+			Location:    resp.Header.Get("Location"),
+			Body:        string(body),
+		}
+	}
+
+	// Elasticsearch reports errors as JSON documents which are decoded by
+	// Do(), anything else most likely comes from a proxy in front of it.
+	// Only HEAD requests get errors without a body.
+	if (len(body) == 0 && req.Method == "HEAD") || isJSON(contentType, body) {
+		return body, statusCode, nil
+	}
+
+	return body, statusCode, &HTTPError{
+		StatusCode:  statusCode,
+		ContentType: contentType,
+		Body:        string(body),
+	}
+}
+
+// isJSON tells whether a response body can be decoded as JSON, using the
+// content type when available and falling back to sniffing the body
+func isJSON(contentType string, body []byte) bool {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+			return true
+		}
 	}
 
-	return body, uint64(resp.StatusCode), nil
+	trimmed := bytes.TrimSpace(body)
+	return len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
 }
@@ -5,9 +5,15 @@
 package goes
 
 import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -294,7 +300,7 @@ func (s *GoesTestSuite) TestBulkSend(c *C) {
 	c.Assert(err, IsNil)
 
 	var expectedTotal uint64 = 2
-	c.Assert(searchResults.Hits.Total, Equals, expectedTotal)
+	c.Assert(searchResults.Hits.Total.Value, Equals, expectedTotal)
 
 	extraDocID := ""
 	checked := 0
@@ -346,7 +352,7 @@ func (s *GoesTestSuite) TestBulkSend(c *C) {
 	c.Assert(err, IsNil)
 
 	expectedTotal = 0
-	c.Assert(searchResults.Hits.Total, Equals, expectedTotal)
+	c.Assert(searchResults.Hits.Total.Value, Equals, expectedTotal)
 
 	_, err = conn.DeleteIndex(indexName)
 	c.Assert(err, IsNil)
@@ -611,7 +617,7 @@ func (s *GoesTestSuite) TestDeleteByQuery(c *C) {
 	//should be 1 doc before delete by query
 	response, err := conn.Search(query, []string{indexName}, []string{docType}, url.Values{})
 	c.Assert(err, IsNil)
-	c.Assert(response.Hits.Total, Equals, uint64(1))
+	c.Assert(response.Hits.Total.Value, Equals, uint64(1))
 
 	response, err = conn.DeleteByQuery(query, []string{indexName}, []string{docType}, url.Values{})
 
@@ -642,7 +648,7 @@ func (s *GoesTestSuite) TestDeleteByQuery(c *C) {
 	//should be 0 docs after delete by query
 	response, err = conn.Search(query, []string{indexName}, []string{docType}, url.Values{})
 	c.Assert(err, IsNil)
-	c.Assert(response.Hits.Total, Equals, uint64(0))
+	c.Assert(response.Hits.Total.Value, Equals, uint64(0))
 }
 
 func (s *GoesTestSuite) TestGet(c *C) {
@@ -760,7 +766,7 @@ func (s *GoesTestSuite) TestSearch(c *C) {
 	response, _ := conn.Search(query, []string{indexName}, []string{docType}, url.Values{})
 
 	expectedHits := Hits{
-		Total:    1,
+		Total:    TotalHits{Value: 1, Relation: TotalHitsEqual},
 		MaxScore: 1.0,
 		Hits: []Hit{
 			{
@@ -776,6 +782,221 @@ func (s *GoesTestSuite) TestSearch(c *C) {
 	c.Assert(response.Hits, DeepEquals, expectedHits)
 }
 
+func (s *GoesTestSuite) TestSearchHitDetails(c *C) {
+	indexName := "testsearchhitdetails"
+	docType := "post"
+
+	conn := NewClient(ESHost, ESPort)
+	if supported, _ := conn.versionAtLeast("5"); !supported {
+		c.Skip("The text and keyword types require ES 5, skipping this test")
+	}
+	mappingType := docType
+	if supported, _ := conn.versionAtLeast("7"); supported {
+		docType = "_doc"
+		mappingType = ""
+	}
+	conn.DeleteIndex(indexName)
+
+	_, err := conn.CreateIndex(indexName, map[string]interface{}{})
+	c.Assert(err, IsNil)
+	defer conn.DeleteIndex(indexName)
+
+	_, err = conn.PutMapping(mappingType, map[string]interface{}{
+		"properties": map[string]interface{}{
+			"title": map[string]interface{}{"type": "text"},
+			"rank":  map[string]interface{}{"type": "integer"},
+			"comments": map[string]interface{}{
+				"type": "nested",
+				"properties": map[string]interface{}{
+					"author": map[string]interface{}{"type": "keyword"},
+				},
+			},
+		},
+	}, []string{indexName})
+	c.Assert(err, IsNil)
+
+	_, err = conn.Index(Document{
+		Index: indexName,
+		Type:  docType,
+		ID:    "1",
+		Fields: map[string]interface{}{
+			"title": "quick brown fox",
+			"rank":  3,
+			"comments": []map[string]interface{}{
+				{"author": "alice"},
+				{"author": "bob"},
+			},
+		},
+	}, url.Values{"routing": []string{"r1"}, "refresh": []string{"true"}})
+	c.Assert(err, IsNil)
+
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must": []map[string]interface{}{
+					{"match": map[string]interface{}{"title": map[string]interface{}{"query": "fox", "_name": "title"}}},
+					{"nested": map[string]interface{}{
+						"path":       "comments",
+						"query":      map[string]interface{}{"term": map[string]interface{}{"comments.author": "bob"}},
+						"inner_hits": map[string]interface{}{},
+					}},
+				},
+			},
+		},
+		"highlight": map[string]interface{}{
+			"fields": map[string]interface{}{"title": map[string]interface{}{}},
+		},
+		"sort":    []interface{}{map[string]interface{}{"rank": "desc"}},
+		"explain": true,
+	}
+	response, err := conn.Search(query, []string{indexName}, nil, url.Values{})
+	c.Assert(err, IsNil)
+	c.Assert(response.Hits.Hits, HasLen, 1)
+
+	hit := response.Hits.Hits[0]
+	c.Assert(hit.Routing, Equals, "r1")
+	c.Assert(hit.Highlight, DeepEquals, map[string][]string{"title": {"quick brown <em>fox</em>"}})
+	c.Assert(hit.MatchedQueries, DeepEquals, []string{"title"})
+	c.Assert(hit.Sort, DeepEquals, []interface{}{json.Number("3")})
+	c.Assert(hit.Explanation, NotNil)
+	c.Assert(hit.Explanation.Description, Not(Equals), "")
+
+	comments := hit.InnerHits["comments"]
+	c.Assert(comments.Total.Value, Equals, uint64(1))
+	c.Assert(comments.Hits, HasLen, 1)
+	c.Assert(comments.Hits[0].Source, DeepEquals, map[string]interface{}{"author": "bob"})
+	c.Assert(comments.Hits[0].Nested, DeepEquals, &NestedIdentity{Field: "comments", Offset: 1})
+}
+
+func (s *GoesTestSuite) TestHitInnerHits(c *C) {
+	var hit Hit
+	err := json.Unmarshal([]byte(`{
+		"_index": "posts", "_type": "_doc", "_id": "1", "_score": null,
+		"_seq_no": 4, "_primary_term": 1,
+		"sort": [3, "fox", 9007199254740993, 1.5],
+		"inner_hits": {
+			"comments": {"hits": {"total": 1, "max_score": 1.5, "hits": [{
+				"_id": "1", "_score": 1.5,
+				"_nested": {"field": "comments", "offset": 1, "_nested": {"field": "replies", "offset": 0}},
+				"_source": {"author": "bob"},
+				"inner_hits": {"votes": {"hits": {"total": 0, "max_score": null, "hits": []}}}
+			}]}}
+		}
+	}`), &hit)
+	c.Assert(err, IsNil)
+
+	c.Assert(hit.SeqNo, Equals, int64(4))
+	c.Assert(hit.Sort, DeepEquals, []interface{}{json.Number("3"), "fox", json.Number("9007199254740993"), json.Number("1.5")})
+	c.Assert(hit.InnerHits, DeepEquals, map[string]Hits{
+		"comments": {
+			Total:    TotalHits{Value: 1, Relation: TotalHitsEqual},
+			MaxScore: 1.5,
+			Hits: []Hit{{
+				ID:    "1",
+				Score: 1.5,
+				Nested: &NestedIdentity{
+					Field:  "comments",
+					Offset: 1,
+					Nested: &NestedIdentity{Field: "replies"},
+				},
+				Source:    map[string]interface{}{"author": "bob"},
+				InnerHits: map[string]Hits{"votes": {Total: TotalHits{Relation: TotalHitsEqual}, Hits: []Hit{}}},
+			}},
+		},
+	})
+}
+
+func (s *GoesTestSuite) TestTrackTotalHits(c *C) {
+	indexName := "testtracktotalhits"
+
+	conn := NewClient(ESHost, ESPort)
+	if supported, _ := conn.versionAtLeast("7"); !supported {
+		c.Skip("Limits of track_total_hits require ES 7, skipping this test")
+	}
+	conn.DeleteIndex(indexName)
+
+	_, err := conn.CreateIndex(indexName, map[string]interface{}{})
+	c.Assert(err, IsNil)
+	defer conn.DeleteIndex(indexName)
+
+	docs := []Document{}
+	for i := 0; i < 3; i++ {
+		docs = append(docs, Document{
+			Index:       indexName,
+			Type:        "_doc",
+			ID:          strconv.Itoa(i),
+			BulkCommand: BulkCommandIndex,
+			Fields:      map[string]interface{}{"user": "foo"},
+		})
+	}
+	_, err = conn.BulkSend(docs)
+	c.Assert(err, IsNil)
+	_, err = conn.RefreshIndex(indexName)
+	c.Assert(err, IsNil)
+
+	query := map[string]interface{}{"size": 0}
+	extraArgs := url.Values{"request_cache": []string{"false"}}
+
+	response, err := conn.Search(query, []string{indexName}, nil, TrackTotalHits(extraArgs, 2))
+	c.Assert(err, IsNil)
+	c.Assert(response.Hits.Total, Equals, TotalHits{Value: 2, Relation: TotalHitsGreaterOrEqual})
+	c.Assert(response.Hits.Total.Exact(), Equals, false)
+
+	response, err = conn.Search(query, []string{indexName}, nil, TrackTotalHits(extraArgs, -1))
+	c.Assert(err, IsNil)
+	c.Assert(response.Hits.Total, Equals, TotalHits{Value: 3, Relation: TotalHitsEqual})
+	c.Assert(response.Hits.Total.Exact(), Equals, true)
+
+	response, err = conn.Search(query, []string{indexName}, nil, TrackTotalHits(extraArgs, 0))
+	c.Assert(err, IsNil)
+	c.Assert(response.Hits.Total, Equals, TotalHits{})
+	c.Assert(extraArgs, HasLen, 1)
+}
+
+func (s *GoesTestSuite) TestTotalHits(c *C) {
+	var queries []string
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("track_total_hits") {
+		case "":
+			w.Write([]byte(`{"hits": {"total": 12, "max_score": null, "hits": []}}`))
+			return
+		case "false":
+			// ES 6 does not count hits either, but reports it with -1
+			w.Write([]byte(`{"hits": {"total": -1, "max_score": null, "hits": []}}`))
+			return
+		}
+		w.Write([]byte(`{"hits": {"total": {"value": 10, "relation": "gte"}, "max_score": null, "hits": []}}`))
+	})
+	defer ts.Close()
+
+	response, err := conn.Search(nil, []string{"a"}, nil, url.Values{})
+	c.Assert(err, IsNil)
+	c.Assert(response.Hits.Total, Equals, TotalHits{Value: 12, Relation: TotalHitsEqual})
+	c.Assert(response.Hits.Total.Exact(), Equals, true)
+
+	response, err = conn.Search(nil, []string{"a"}, nil, TrackTotalHits(nil, 10))
+	c.Assert(err, IsNil)
+	c.Assert(response.Hits.Total, Equals, TotalHits{Value: 10, Relation: TotalHitsGreaterOrEqual})
+	c.Assert(response.Hits.Total.Exact(), Equals, false)
+
+	_, err = conn.Search(nil, []string{"a"}, nil, TrackTotalHits(url.Values{"size": []string{"0"}}, -1))
+	c.Assert(err, IsNil)
+
+	response, err = conn.Search(nil, []string{"a"}, nil, TrackTotalHits(nil, 0))
+	c.Assert(err, IsNil)
+	c.Assert(response.Hits.Total, Equals, TotalHits{})
+	c.Assert(response.Hits.Total.Exact(), Equals, false)
+
+	c.Assert(queries, DeepEquals, []string{
+		"",
+		"track_total_hits=10",
+		"size=0&track_total_hits=true",
+		"track_total_hits=false",
+	})
+}
+
 func (s *GoesTestSuite) TestCount(c *C) {
 	indexName := "testcount"
 	docType := "tweet"
@@ -998,7 +1219,7 @@ func (s *GoesTestSuite) TestScroll(c *C) {
 	}
 
 	// some data in first chunk
-	c.Assert(searchResults.Hits.Total, Equals, uint64(2))
+	c.Assert(searchResults.Hits.Total.Value, Equals, uint64(2))
 	c.Assert(len(searchResults.ScrollID) > 0, Equals, true)
 	c.Assert(len(searchResults.Hits.Hits), Equals, 1)
 
@@ -1006,7 +1227,7 @@ func (s *GoesTestSuite) TestScroll(c *C) {
 	c.Assert(err, IsNil)
 
 	// more data in second chunk
-	c.Assert(searchResults.Hits.Total, Equals, uint64(2))
+	c.Assert(searchResults.Hits.Total.Value, Equals, uint64(2))
 	c.Assert(len(searchResults.ScrollID) > 0, Equals, true)
 	c.Assert(len(searchResults.Hits.Hits), Equals, 1)
 
@@ -1014,7 +1235,7 @@ func (s *GoesTestSuite) TestScroll(c *C) {
 	c.Assert(err, IsNil)
 
 	// nothing in third chunk
-	c.Assert(searchResults.Hits.Total, Equals, uint64(2))
+	c.Assert(searchResults.Hits.Total.Value, Equals, uint64(2))
 	c.Assert(len(searchResults.ScrollID) > 0, Equals, true)
 	c.Assert(len(searchResults.Hits.Hits), Equals, 0)
 }
@@ -1508,3 +1729,419 @@ func (s *GoesTestSuite) TestAliasExists(c *C) {
 	exists, _ = conn.AliasExists(alias)
 	c.Assert(exists, Equals, true)
 }
+
+// newTestServer starts an HTTP server answering with handler and returns a
+// client connected to it
+func newTestServer(c *C, handler http.HandlerFunc) (*httptest.Server, *Client) {
+	ts := httptest.NewServer(handler)
+	u, err := url.Parse(ts.URL)
+	c.Assert(err, IsNil)
+	host, port, err := net.SplitHostPort(u.Host)
+	c.Assert(err, IsNil)
+
+	return ts, NewClient(host, port)
+}
+
+func (s *GoesTestSuite) TestDoAccepted(c *C) {
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"acknowledged": true}`))
+	})
+	defer ts.Close()
+
+	resp, err := conn.Do(&Request{Method: "POST", API: "_reindex"})
+	c.Assert(err, IsNil)
+	c.Assert(resp.Status, Equals, uint64(202))
+	c.Assert(resp.Acknowledged, Equals, true)
+}
+
+func (s *GoesTestSuite) TestDoRedirect(c *C) {
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/moved/_search" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"took": 1}`))
+			return
+		}
+		http.Redirect(w, r, "/moved/_search", http.StatusMovedPermanently)
+	})
+	defer ts.Close()
+
+	r := Request{Method: "GET", IndexList: []string{"i"}, API: "_search"}
+
+	resp, err := conn.Do(&r)
+	c.Assert(err, IsNil)
+	c.Assert(resp.Took, Equals, uint64(1))
+
+	conn.WithHTTPClient(&http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	})
+	resp, err = conn.Do(&r)
+	c.Assert(err, ErrorMatches, `\[301\] redirected to /moved/_search`)
+	c.Assert(resp.Status, Equals, uint64(301))
+	c.Assert(err.(*HTTPError).Location, Equals, "/moved/_search")
+}
+
+func (s *GoesTestSuite) TestDoRedirectWithBody(c *C) {
+	var requests []string
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+		switch r.URL.Path {
+		case "/moved/_search":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"took": 1}`))
+		case "/temporary/_search":
+			http.Redirect(w, r, "/moved/_search", http.StatusTemporaryRedirect)
+		default:
+			http.Redirect(w, r, "/moved/_search", http.StatusMovedPermanently)
+		}
+	})
+	defer ts.Close()
+
+	// A 301 would turn the search into a GET without its query
+	query := map[string]interface{}{"query": map[string]interface{}{"term": map[string]interface{}{"user": "foo"}}}
+	resp, err := conn.Search(query, []string{"i"}, nil, url.Values{})
+	c.Assert(err, ErrorMatches, `\[301\] redirected to /moved/_search`)
+	c.Assert(resp.Status, Equals, uint64(301))
+
+	_, err = conn.DeleteIndex("i")
+	c.Assert(err, DeepEquals, &HTTPError{StatusCode: 301, Location: "/moved/_search"})
+
+	// A 307 replays the method and the body
+	resp, err = conn.Search(query, []string{"temporary"}, nil, url.Values{})
+	c.Assert(err, IsNil)
+	c.Assert(resp.Took, Equals, uint64(1))
+
+	body := `{"query":{"term":{"user":"foo"}}}`
+	c.Assert(requests, DeepEquals, []string{
+		"POST /i/_search " + body,
+		"DELETE /i/ ",
+		"POST /temporary/_search " + body,
+		"POST /moved/_search " + body,
+	})
+}
+
+func (s *GoesTestSuite) TestDoNonJSONError(c *C) {
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("<html>502 Bad Gateway</html>"))
+	})
+	defer ts.Close()
+
+	resp, err := conn.Do(&Request{Method: "GET", API: "_search"})
+	c.Assert(err, ErrorMatches, `\[502\] <html>502 Bad Gateway</html>`)
+	c.Assert(resp.Status, Equals, uint64(502))
+	c.Assert(err, DeepEquals, &HTTPError{
+		StatusCode:  502,
+		ContentType: "text/html",
+		Body:        "<html>502 Bad Gateway</html>",
+	})
+
+	body, status, err := conn.DoRaw(&Request{Method: "GET", API: "_search"})
+	c.Assert(err, NotNil)
+	c.Assert(status, Equals, uint64(502))
+	c.Assert(string(body), Equals, "<html>502 Bad Gateway</html>")
+
+	ts, conn = newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer ts.Close()
+
+	resp, err = conn.Do(&Request{Method: "GET", API: "_search"})
+	c.Assert(resp.Status, Equals, uint64(503))
+	c.Assert(err, DeepEquals, &HTTPError{StatusCode: 503})
+}
+
+func (s *GoesTestSuite) TestDoJSONError(c *C) {
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "HEAD" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": "SearchParseException", "status": 400}`))
+	})
+	defer ts.Close()
+
+	resp, err := conn.Do(&Request{Method: "GET", API: "_search"})
+	c.Assert(err, DeepEquals, &SearchError{"SearchParseException", 400})
+	c.Assert(resp.Status, Equals, uint64(400))
+
+	exists, err := conn.IndicesExist([]string{"i"})
+	c.Assert(err, IsNil)
+	c.Assert(exists, Equals, false)
+}
+
+func (s *GoesTestSuite) TestMGet(c *C) {
+	indexName := "testmget"
+	docType := "tweet"
+
+	conn := NewClient(ESHost, ESPort)
+	conn.DeleteIndex(indexName)
+
+	_, err := conn.CreateIndex(indexName, map[string]interface{}{})
+	c.Assert(err, IsNil)
+	defer conn.DeleteIndex(indexName)
+
+	for _, id := range []string{"1", "2"} {
+		d := Document{
+			Index: indexName,
+			Type:  docType,
+			ID:    id,
+			Fields: map[string]interface{}{
+				"user":    "foo" + id,
+				"message": "bar" + id,
+			},
+		}
+		_, err = conn.Index(d, url.Values{})
+		c.Assert(err, IsNil)
+	}
+
+	response, err := conn.MGet([]MGetDoc{
+		{Index: indexName, Type: docType, ID: "2"},
+		{Index: indexName, Type: docType, ID: "3"},
+		{Index: indexName, Type: docType, ID: "1", Source: []string{"user"}},
+	}, url.Values{})
+	c.Assert(err, IsNil)
+
+	c.Assert(response.Docs, DeepEquals, []Response{
+		{
+			Index:   indexName,
+			Type:    docType,
+			ID:      "2",
+			Version: 1,
+			Found:   true,
+			Source:  map[string]interface{}{"user": "foo2", "message": "bar2"},
+		},
+		{
+			Index: indexName,
+			Type:  docType,
+			ID:    "3",
+		},
+		{
+			Index:   indexName,
+			Type:    docType,
+			ID:      "1",
+			Version: 1,
+			Found:   true,
+			Source:  map[string]interface{}{"user": "foo1"},
+		},
+	})
+
+	response, err = conn.MGetIDs(indexName, docType, []string{"1", "3"}, url.Values{"_source": []string{"message"}})
+	c.Assert(err, IsNil)
+
+	c.Assert(response.Docs, HasLen, 2)
+	c.Assert(response.Docs[0].Found, Equals, true)
+	c.Assert(response.Docs[0].Source, DeepEquals, map[string]interface{}{"message": "bar1"})
+	c.Assert(response.Docs[1].Found, Equals, false)
+}
+
+func (s *GoesTestSuite) TestMGetDocError(c *C) {
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/_mget")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"docs": [
+			{"_index": "a", "_type": "t", "_id": "1", "found": true, "_source": {"f": 1}},
+			{"_index": "b", "_type": "t", "_id": "2", "error": "[b] missing"},
+			{"_index": "c", "_type": "t", "_id": "3", "error": {"type": "index_not_found_exception"}}
+		]}`))
+	})
+	defer ts.Close()
+
+	response, err := conn.MGet([]MGetDoc{
+		{Index: "a", ID: "1"},
+		{Index: "b", ID: "2"},
+		{Index: "c", ID: "3"},
+	}, nil)
+	c.Assert(err, IsNil)
+
+	c.Assert(response.Docs, HasLen, 3)
+	c.Assert(response.Docs[0].Found, Equals, true)
+	c.Assert(response.Docs[0].Source, DeepEquals, map[string]interface{}{"f": 1.0})
+	c.Assert(response.Docs[1].Error, Equals, "[b] missing")
+	c.Assert(response.Docs[1].RawError, IsNil)
+	c.Assert(response.Docs[2].Error, Equals, `{"type": "index_not_found_exception"}`)
+}
+
+func (s *GoesTestSuite) TestMSearch(c *C) {
+	indexName := "testmsearch"
+	docType := "tweet"
+
+	conn := NewClient(ESHost, ESPort)
+	conn.DeleteIndex(indexName)
+
+	_, err := conn.CreateIndex(indexName, map[string]interface{}{})
+	c.Assert(err, IsNil)
+	defer conn.DeleteIndex(indexName)
+
+	d := Document{
+		Index: indexName,
+		Type:  docType,
+		ID:    "1",
+		Fields: map[string]interface{}{
+			"user":    "foo",
+			"message": "bar",
+		},
+	}
+	_, err = conn.Index(d, url.Values{})
+	c.Assert(err, IsNil)
+
+	_, err = conn.RefreshIndex(indexName)
+	c.Assert(err, IsNil)
+
+	queries := []MSearchQuery{
+		{
+			Query: map[string]interface{}{
+				"query": map[string]interface{}{
+					"match_all": map[string]interface{}{},
+				},
+			},
+		},
+		{
+			Header: map[string]interface{}{"index": "testmsearchmissing"},
+		},
+		{
+			Query: map[string]interface{}{
+				"query": map[string]interface{}{
+					"term": map[string]interface{}{"user": "nobody"},
+				},
+			},
+		},
+	}
+
+	responses, err := conn.MSearch(queries, []string{indexName}, []string{docType}, url.Values{})
+	c.Assert(err, IsNil)
+	c.Assert(responses, HasLen, 3)
+
+	c.Assert(responses[0].Error, Equals, "")
+	c.Assert(responses[0].Hits.Total.Value, Equals, uint64(1))
+	c.Assert(responses[0].Hits.Hits[0].ID, Equals, "1")
+
+	c.Assert(responses[1].Error, Matches, ".*testmsearchmissing.*")
+
+	c.Assert(responses[2].Error, Equals, "")
+	c.Assert(responses[2].Hits.Total.Value, Equals, uint64(0))
+}
+
+func (s *GoesTestSuite) TestMSearchBody(c *C) {
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		c.Check(err, IsNil)
+		c.Check(r.URL.Path, Equals, "/a/_msearch")
+		c.Check(string(body), Equals, "{}\n{\"size\":1}\n{\"index\":\"b\"}\n{}\n")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"responses": [
+			{"took": 1, "hits": {"total": 3, "hits": []}, "status": 200},
+			{"error": {"type": "index_not_found_exception"}, "status": 404}
+		]}`))
+	})
+	defer ts.Close()
+
+	responses, err := conn.MSearch([]MSearchQuery{
+		{Query: map[string]interface{}{"size": 1}},
+		{Header: map[string]interface{}{"index": "b"}},
+	}, []string{"a"}, nil, nil)
+	c.Assert(err, IsNil)
+
+	c.Assert(responses, HasLen, 2)
+	c.Assert(responses[0].Status, Equals, uint64(200))
+	c.Assert(responses[0].Hits.Total.Value, Equals, uint64(3))
+	c.Assert(responses[1].Status, Equals, uint64(404))
+	c.Assert(responses[1].Error, Equals, `{"type": "index_not_found_exception"}`)
+}
+
+func (s *GoesTestSuite) TestOptimisticConcurrency(c *C) {
+	indexName := "testoptimisticconcurrency"
+	docType := "tweet"
+	docID := "1234"
+
+	conn := NewClient(ESHost, ESPort)
+	if version, _ := conn.Version(); version < "6.7" {
+		c.Skip("if_seq_no is not supported before ES 6.7, skipping this test")
+	}
+	conn.DeleteIndex(indexName)
+
+	_, err := conn.CreateIndex(indexName, map[string]interface{}{})
+	c.Assert(err, IsNil)
+	defer conn.DeleteIndex(indexName)
+
+	d := Document{
+		Index:  indexName,
+		Type:   docType,
+		ID:     docID,
+		Fields: map[string]interface{}{"counter": 1},
+	}
+	created, err := conn.Index(d, url.Values{})
+	c.Assert(err, IsNil)
+	c.Assert(created.PrimaryTerm, Equals, int64(1))
+
+	d.IfSeqNo = created.SeqNo
+	d.IfPrimaryTerm = created.PrimaryTerm
+	d.Fields = map[string]interface{}{"counter": 2}
+	updated, err := conn.Index(d, url.Values{})
+	c.Assert(err, IsNil)
+	c.Assert(updated.SeqNo > created.SeqNo, Equals, true)
+
+	// d still holds the _seq_no of the first version
+	_, err = conn.Index(d, url.Values{})
+	c.Assert(IsConflict(err), Equals, true)
+
+	_, err = conn.Delete(d, url.Values{})
+	c.Assert(IsConflict(err), Equals, true)
+
+	response, err := conn.ReadModifyWrite(indexName, docType, docID, 3, func(source map[string]interface{}) (interface{}, error) {
+		source["counter"] = source["counter"].(float64) + 1
+		return source, nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(response.Version, Equals, 3)
+
+	response, err = conn.Get(indexName, docType, docID, url.Values{})
+	c.Assert(err, IsNil)
+	c.Assert(response.Source["counter"], Equals, 3.0)
+}
+
+func (s *GoesTestSuite) TestReadModifyWriteRetry(c *C) {
+	writes := 0
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method == "GET" {
+			fmt.Fprintf(w, `{"found": true, "_version": %d, "_seq_no": %d, "_primary_term": 1, "_source": {"counter": %d}}`, writes+1, writes, writes)
+			return
+		}
+
+		c.Check(r.URL.Query().Get("if_seq_no"), Equals, strconv.Itoa(writes))
+		c.Check(r.URL.Query().Get("if_primary_term"), Equals, "1")
+
+		writes++
+		if writes < 3 {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error": "version_conflict_engine_exception", "status": 409}`))
+			return
+		}
+		w.Write([]byte(`{"_version": 4, "_seq_no": 3, "_primary_term": 1}`))
+	})
+	defer ts.Close()
+
+	modify := func(source map[string]interface{}) (interface{}, error) {
+		source["counter"] = source["counter"].(float64) + 1
+		return source, nil
+	}
+
+	_, err := conn.ReadModifyWrite("i", "t", "1", 1, modify)
+	c.Assert(IsConflict(err), Equals, true)
+	c.Assert(writes, Equals, 2)
+
+	response, err := conn.ReadModifyWrite("i", "t", "1", 1, modify)
+	c.Assert(err, IsNil)
+	c.Assert(writes, Equals, 3)
+	c.Assert(response.SeqNo, Equals, int64(3))
+}
@@ -0,0 +1,185 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+// LifecyclePolicy holds an index lifecycle management (ILM) policy
+type LifecyclePolicy struct {
+	Phases LifecyclePhases        `json:"phases"`
+	Meta   map[string]interface{} `json:"_meta,omitempty"`
+}
+
+// LifecyclePhases holds the phases an index goes through, in order
+type LifecyclePhases struct {
+	Hot    *LifecyclePhase `json:"hot,omitempty"`
+	Warm   *LifecyclePhase `json:"warm,omitempty"`
+	Cold   *LifecyclePhase `json:"cold,omitempty"`
+	Delete *LifecyclePhase `json:"delete,omitempty"`
+}
+
+// LifecyclePhase holds the actions run once an index entered a phase, MinAge
+// after its creation or rollover
+type LifecyclePhase struct {
+	MinAge  string           `json:"min_age,omitempty"`
+	Actions LifecycleActions `json:"actions"`
+}
+
+// LifecycleActions holds the actions of a phase, the ones without parameters
+// are enabled by setting them to &struct{}{}
+type LifecycleActions struct {
+	Rollover        *RolloverConditions       `json:"rollover,omitempty"`
+	SetPriority     *LifecyclePriority        `json:"set_priority,omitempty"`
+	Allocate        *LifecycleAllocate        `json:"allocate,omitempty"`
+	Shrink          *LifecycleShrink          `json:"shrink,omitempty"`
+	ForceMerge      *LifecycleForceMerge      `json:"forcemerge,omitempty"`
+	ReadOnly        *struct{}                 `json:"readonly,omitempty"`
+	Freeze          *struct{}                 `json:"freeze,omitempty"`
+	Unfollow        *struct{}                 `json:"unfollow,omitempty"`
+	WaitForSnapshot *LifecycleWaitForSnapshot `json:"wait_for_snapshot,omitempty"`
+	Delete          *struct{}                 `json:"delete,omitempty"`
+}
+
+// LifecyclePriority sets the recovery priority of an index
+type LifecyclePriority struct {
+	Priority int `json:"priority"`
+}
+
+// LifecycleAllocate moves the shards of an index to other nodes or changes
+// its number of replicas
+type LifecycleAllocate struct {
+	NumberOfReplicas *int              `json:"number_of_replicas,omitempty"`
+	Include          map[string]string `json:"include,omitempty"`
+	Exclude          map[string]string `json:"exclude,omitempty"`
+	Require          map[string]string `json:"require,omitempty"`
+}
+
+// LifecycleShrink shrinks an index to fewer primary shards
+type LifecycleShrink struct {
+	NumberOfShards int `json:"number_of_shards"`
+}
+
+// LifecycleForceMerge force merges an index into at most MaxNumSegments
+// segments
+type LifecycleForceMerge struct {
+	MaxNumSegments int `json:"max_num_segments"`
+}
+
+// LifecycleWaitForSnapshot waits for a snapshot lifecycle policy to run before
+// deleting an index
+type LifecycleWaitForSnapshot struct {
+	Policy string `json:"policy"`
+}
+
+// LifecyclePolicyInfo holds a policy as returned by GetLifecyclePolicy
+type LifecyclePolicyInfo struct {
+	Version      int             `json:"version"`
+	ModifiedDate string          `json:"modified_date"`
+	Policy       LifecyclePolicy `json:"policy"`
+}
+
+// LifecycleStepKey identifies a step of a policy
+type LifecycleStepKey struct {
+	Phase  string `json:"phase"`
+	Action string `json:"action,omitempty"`
+	Name   string `json:"name,omitempty"`
+}
+
+// LifecycleExplain holds the lifecycle status of an index
+type LifecycleExplain struct {
+	Index                string                 `json:"index"`
+	Managed              bool                   `json:"managed"`
+	Policy               string                 `json:"policy"`
+	LifecycleDateMillis  int64                  `json:"lifecycle_date_millis"`
+	Age                  string                 `json:"age"`
+	Phase                string                 `json:"phase"`
+	PhaseTimeMillis      int64                  `json:"phase_time_millis"`
+	Action               string                 `json:"action"`
+	ActionTimeMillis     int64                  `json:"action_time_millis"`
+	Step                 string                 `json:"step"`
+	StepTimeMillis       int64                  `json:"step_time_millis"`
+	FailedStep           string                 `json:"failed_step"`
+	FailedStepRetryCount int                    `json:"failed_step_retry_count"`
+	StepInfo             map[string]interface{} `json:"step_info"`
+	PhaseExecution       map[string]interface{} `json:"phase_execution"`
+	IsAutoRetryableError bool                   `json:"is_auto_retryable_error"`
+}
+
+// PutLifecyclePolicy creates or replaces a lifecycle policy
+func (c *Client) PutLifecyclePolicy(name string, policy LifecyclePolicy) (*Response, error) {
+	r := Request{
+		Query:  map[string]interface{}{"policy": policy},
+		Method: "PUT",
+		API:    "_ilm/policy/" + name,
+	}
+
+	return c.Do(&r)
+}
+
+// GetLifecyclePolicy returns the lifecycle policies by name, all of them if no
+// name is given
+func (c *Client) GetLifecyclePolicy(names []string) (map[string]LifecyclePolicyInfo, error) {
+	r := Request{
+		Method: "GET",
+		API:    namedAPI("_ilm/policy", names),
+	}
+
+	policies := map[string]LifecyclePolicyInfo{}
+	return policies, c.doInto(&r, &policies)
+}
+
+// DeleteLifecyclePolicy deletes a lifecycle policy, which must not be used
+// by any index
+func (c *Client) DeleteLifecyclePolicy(name string) (*Response, error) {
+	r := Request{
+		Method: "DELETE",
+		API:    "_ilm/policy/" + name,
+	}
+
+	return c.Do(&r)
+}
+
+// ExplainLifecycle returns the lifecycle status of the indexes matching
+// index, by index name
+func (c *Client) ExplainLifecycle(index string) (map[string]LifecycleExplain, error) {
+	r := Request{
+		IndexList: []string{index},
+		Method:    "GET",
+		API:       "_ilm/explain",
+	}
+
+	var resp struct {
+		Indices map[string]LifecycleExplain `json:"indices"`
+	}
+	if err := c.doInto(&r, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Indices, nil
+}
+
+// RetryLifecycle retries the failed step of the indexes matching index
+func (c *Client) RetryLifecycle(index string) (*Response, error) {
+	r := Request{
+		IndexList: []string{index},
+		Method:    "POST",
+		API:       "_ilm/retry",
+	}
+
+	return c.Do(&r)
+}
+
+// MoveToLifecycleStep moves an index from its current step to another one.
+// The move is rejected if the index is not at currentStep anymore.
+func (c *Client) MoveToLifecycleStep(index string, currentStep LifecycleStepKey, nextStep LifecycleStepKey) (*Response, error) {
+	r := Request{
+		Query: map[string]interface{}{
+			"current_step": currentStep,
+			"next_step":    nextStep,
+		},
+		Method: "POST",
+		API:    "_ilm/move/" + index,
+	}
+
+	return c.Do(&r)
+}
@@ -0,0 +1,107 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	. "github.com/go-check/check"
+)
+
+func (s *GoesTestSuite) TestLifecyclePolicy(c *C) {
+	policyName := "testlifecyclepolicy"
+	indexName := "testlifecyclepolicy-000001"
+
+	conn := NewClient(ESHost, ESPort)
+	if supported, _ := conn.versionAtLeast("6.6"); !supported {
+		c.Skip("Index lifecycle management requires ES 6.6, skipping this test")
+	}
+	conn.DeleteIndex(indexName)
+	conn.DeleteLifecyclePolicy(policyName)
+
+	policy := LifecyclePolicy{
+		Phases: LifecyclePhases{
+			Hot: &LifecyclePhase{
+				MinAge: "0ms",
+				Actions: LifecycleActions{
+					Rollover:    &RolloverConditions{MaxAge: "30d", MaxSize: "50gb"},
+					SetPriority: &LifecyclePriority{Priority: 100},
+				},
+			},
+			Warm: &LifecyclePhase{
+				MinAge: "7d",
+				Actions: LifecycleActions{
+					ReadOnly:   &struct{}{},
+					ForceMerge: &LifecycleForceMerge{MaxNumSegments: 1},
+				},
+			},
+			Delete: &LifecyclePhase{
+				MinAge:  "90d",
+				Actions: LifecycleActions{Delete: &struct{}{}},
+			},
+		},
+	}
+
+	_, err := conn.PutLifecyclePolicy(policyName, policy)
+	c.Assert(err, IsNil)
+	defer conn.DeleteLifecyclePolicy(policyName)
+
+	policies, err := conn.GetLifecyclePolicy([]string{policyName})
+	c.Assert(err, IsNil)
+	c.Assert(policies[policyName].Version, Equals, 1)
+	c.Assert(policies[policyName].Policy.Phases.Hot.Actions.Rollover, DeepEquals, policy.Phases.Hot.Actions.Rollover)
+	c.Assert(policies[policyName].Policy.Phases.Warm.Actions.ReadOnly, NotNil)
+	c.Assert(policies[policyName].Policy.Phases.Cold, IsNil)
+
+	_, err = conn.CreateIndex(indexName, map[string]interface{}{
+		"settings": map[string]interface{}{
+			"index.lifecycle.name":           policyName,
+			"index.lifecycle.rollover_alias": "testlifecyclepolicy",
+		},
+		"aliases": map[string]interface{}{
+			"testlifecyclepolicy": map[string]interface{}{"is_write_index": true},
+		},
+	})
+	c.Assert(err, IsNil)
+	defer conn.DeleteIndex(indexName)
+
+	explain, err := conn.ExplainLifecycle(indexName)
+	c.Assert(err, IsNil)
+	c.Assert(explain[indexName].Managed, Equals, true)
+	c.Assert(explain[indexName].Policy, Equals, policyName)
+
+	// Nothing failed, there is nothing to retry
+	_, err = conn.RetryLifecycle(indexName)
+	c.Assert(err, NotNil)
+
+	_, err = conn.DeleteLifecyclePolicy(policyName)
+	c.Assert(err, NotNil)
+}
+
+func (s *GoesTestSuite) TestMoveToLifecycleStep(c *C) {
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/_ilm/move/logs-1")
+
+		var body map[string]interface{}
+		data, _ := ioutil.ReadAll(r.Body)
+		c.Check(json.Unmarshal(data, &body), IsNil)
+		c.Check(body, DeepEquals, map[string]interface{}{
+			"current_step": map[string]interface{}{"phase": "new", "action": "complete", "name": "complete"},
+			"next_step":    map[string]interface{}{"phase": "warm"},
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"acknowledged": true}`))
+	})
+	defer ts.Close()
+
+	response, err := conn.MoveToLifecycleStep("logs-1",
+		LifecycleStepKey{Phase: "new", Action: "complete", Name: "complete"},
+		LifecycleStepKey{Phase: "warm"})
+	c.Assert(err, IsNil)
+	c.Assert(response.Acknowledged, Equals, true)
+}
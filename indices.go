@@ -0,0 +1,173 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// IndexOptions holds the parameters of the index management operations
+type IndexOptions struct {
+	// WaitForActiveShards is the number of copies of each shard, or "all",
+	// which must be active before the operation returns, otherwise
+	// ShardsAcknowledged is false
+	WaitForActiveShards string
+
+	// Timeout of the acknowledgement by the nodes, e.g. "30s"
+	Timeout string
+
+	// MasterTimeout of the connection to the master node, e.g. "30s"
+	MasterTimeout string
+
+	// ExtraArgs are added to the query string
+	ExtraArgs url.Values
+}
+
+// AckResponse holds the acknowledgement of an index management operation.
+// Use IndexOptions.WaitForActiveShards to control ShardsAcknowledged.
+type AckResponse struct {
+	Acknowledged       bool   `json:"acknowledged"`
+	ShardsAcknowledged bool   `json:"shards_acknowledged"`
+	Index              string `json:"index"`
+}
+
+// RolloverConditions holds the conditions of which at least one must be met
+// for an alias to be rolled over
+type RolloverConditions struct {
+	MaxAge  string `json:"max_age,omitempty"`
+	MaxDocs int64  `json:"max_docs,omitempty"`
+	MaxSize string `json:"max_size,omitempty"`
+}
+
+// RolloverBody describes a rollover: its conditions, and the settings,
+// mappings and aliases of the new index
+type RolloverBody struct {
+	Conditions RolloverConditions `json:"conditions"`
+	TemplateBody
+}
+
+// RolloverResponse holds the response of a rollover
+type RolloverResponse struct {
+	AckResponse
+	OldIndex   string          `json:"old_index"`
+	NewIndex   string          `json:"new_index"`
+	RolledOver bool            `json:"rolled_over"`
+	DryRun     bool            `json:"dry_run"`
+	Conditions map[string]bool `json:"conditions"`
+}
+
+// OpenIndex opens a closed index
+func (c *Client) OpenIndex(name string, options IndexOptions) (*AckResponse, error) {
+	return c.ackRequest([]string{name}, "_open", nil, options)
+}
+
+// CloseIndex closes an index, which then can not be read or written until
+// it is opened again
+func (c *Client) CloseIndex(name string, options IndexOptions) (*AckResponse, error) {
+	return c.ackRequest([]string{name}, "_close", nil, options)
+}
+
+// Shrink copies a read-only index into a new index with fewer primary shards.
+// body holds the settings and aliases of the target index, it may be nil.
+func (c *Client) Shrink(source string, target string, body interface{}, options IndexOptions) (*AckResponse, error) {
+	return c.ackRequest([]string{source}, "_shrink/"+target, body, options)
+}
+
+// Split copies a read-only index into a new index with more primary shards.
+// body holds the settings and aliases of the target index, it must at least
+// set index.number_of_shards.
+func (c *Client) Split(source string, target string, body interface{}, options IndexOptions) (*AckResponse, error) {
+	return c.ackRequest([]string{source}, "_split/"+target, body, options)
+}
+
+// Clone copies a read-only index into a new index with the same number of
+// primary shards. body holds the settings and aliases of the target index, it
+// may be nil.
+func (c *Client) Clone(source string, target string, body interface{}, options IndexOptions) (*AckResponse, error) {
+	return c.ackRequest([]string{source}, "_clone/"+target, body, options)
+}
+
+// Rollover points alias to a new index if the current one meets any of the
+// conditions. newIndex may be empty to let elasticsearch increment the
+// number at the end of the current index name. With dryRun, the conditions
+// are checked without rolling over.
+func (c *Client) Rollover(alias string, newIndex string, body RolloverBody, dryRun bool, options IndexOptions) (*RolloverResponse, error) {
+	api := "_rollover"
+	if newIndex != "" {
+		api += "/" + newIndex
+	}
+
+	args := options.args(1)
+	if dryRun {
+		args.Set("dry_run", strconv.FormatBool(dryRun))
+	}
+
+	r := Request{
+		Query:     body,
+		IndexList: []string{alias},
+		Method:    "POST",
+		API:       api,
+		ExtraArgs: args,
+	}
+
+	resp := &RolloverResponse{}
+	return resp, c.doInto(&r, resp)
+}
+
+// Flush flushes the indexes, writing their transaction log to disk
+func (c *Client) Flush(indexList []string, extraArgs url.Values) (*Response, error) {
+	r := Request{
+		IndexList: indexList,
+		ExtraArgs: extraArgs,
+		Method:    "POST",
+		API:       "_flush",
+	}
+
+	return c.Do(&r)
+}
+
+// ClearCache clears the caches of the indexes. extraArgs can restrict it to
+// some caches, for example with query=true, fielddata=true or request=true.
+func (c *Client) ClearCache(indexList []string, extraArgs url.Values) (*Response, error) {
+	r := Request{
+		IndexList: indexList,
+		ExtraArgs: extraArgs,
+		Method:    "POST",
+		API:       "_cache/clear",
+	}
+
+	return c.Do(&r)
+}
+
+func (c *Client) ackRequest(indexList []string, api string, body interface{}, options IndexOptions) (*AckResponse, error) {
+	r := Request{
+		Query:     body,
+		IndexList: indexList,
+		ExtraArgs: options.args(0),
+		Method:    "POST",
+		API:       api,
+	}
+
+	resp := &AckResponse{}
+	return resp, c.doInto(&r, resp)
+}
+
+// args returns the options as query string parameters, with room for extra
+// other parameters
+func (o IndexOptions) args(extra int) url.Values {
+	args := copyArgs(o.ExtraArgs, extra+3)
+	if o.WaitForActiveShards != "" {
+		args.Set("wait_for_active_shards", o.WaitForActiveShards)
+	}
+	if o.Timeout != "" {
+		args.Set("timeout", o.Timeout)
+	}
+	if o.MasterTimeout != "" {
+		args.Set("master_timeout", o.MasterTimeout)
+	}
+
+	return args
+}
@@ -0,0 +1,193 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	. "github.com/go-check/check"
+)
+
+func (s *GoesTestSuite) TestOpenCloseIndex(c *C) {
+	indexName := "testopencloseindex"
+
+	conn := NewClient(ESHost, ESPort)
+	conn.DeleteIndex(indexName)
+
+	_, err := conn.CreateIndex(indexName, map[string]interface{}{})
+	c.Assert(err, IsNil)
+	defer conn.DeleteIndex(indexName)
+
+	response, err := conn.CloseIndex(indexName, IndexOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(response.Acknowledged, Equals, true)
+
+	_, err = conn.Search(map[string]interface{}{}, []string{indexName}, nil, url.Values{})
+	c.Assert(err, NotNil)
+
+	response, err = conn.OpenIndex(indexName, IndexOptions{WaitForActiveShards: "1"})
+	c.Assert(err, IsNil)
+	c.Assert(response.Acknowledged, Equals, true)
+
+	_, err = conn.Flush([]string{indexName}, url.Values{})
+	c.Assert(err, IsNil)
+
+	_, err = conn.ClearCache([]string{indexName}, url.Values{})
+	c.Assert(err, IsNil)
+}
+
+func (s *GoesTestSuite) TestShrinkSplitClone(c *C) {
+	indexName := "testresizeindex"
+	shrunk := "testresizeindex_shrunk"
+	split := "testresizeindex_split"
+	cloned := "testresizeindex_cloned"
+
+	conn := NewClient(ESHost, ESPort)
+	if version, _ := conn.Version(); version < "5" {
+		c.Skip("Shrinking indexes requires ES 5.x, skipping this test")
+	}
+	for _, index := range []string{indexName, shrunk, split, cloned} {
+		conn.DeleteIndex(index)
+		defer conn.DeleteIndex(index)
+	}
+
+	settings := map[string]interface{}{
+		"index.number_of_shards":   2,
+		"index.number_of_replicas": 0,
+		"index.blocks.write":       true,
+	}
+	// Splitting requires the number of routing shards to be set before ES 7.0
+	if supported, _ := conn.versionAtLeast("6.1"); supported {
+		settings["index.number_of_routing_shards"] = 4
+	}
+	_, err := conn.CreateIndex(indexName, map[string]interface{}{"settings": settings})
+	c.Assert(err, IsNil)
+
+	_, err = conn.Do(&Request{
+		Method: "GET",
+		API:    "_cluster/health/" + indexName,
+		ExtraArgs: url.Values{
+			"wait_for_status": []string{"green"},
+		},
+	})
+	c.Assert(err, IsNil)
+
+	response, err := conn.Shrink(indexName, shrunk, map[string]interface{}{
+		"settings": map[string]interface{}{"index.number_of_shards": 1},
+	}, IndexOptions{WaitForActiveShards: "1"})
+	c.Assert(err, IsNil)
+	c.Assert(response.Acknowledged, Equals, true)
+	c.Assert(response.ShardsAcknowledged, Equals, true)
+	c.Assert(response.Index, Equals, shrunk)
+
+	if supported, _ := conn.versionAtLeast("6.1"); !supported {
+		return
+	}
+	response, err = conn.Split(indexName, split, map[string]interface{}{
+		"settings": map[string]interface{}{"index.number_of_shards": 4},
+	}, IndexOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(response.Acknowledged, Equals, true)
+
+	if supported, _ := conn.versionAtLeast("7.4"); !supported {
+		return
+	}
+	response, err = conn.Clone(indexName, cloned, nil, IndexOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(response.Acknowledged, Equals, true)
+}
+
+func (s *GoesTestSuite) TestRollover(c *C) {
+	alias := "testrollover"
+	first := "testrollover-000001"
+	second := "testrollover-000002"
+
+	conn := NewClient(ESHost, ESPort)
+	if version, _ := conn.Version(); version < "5" {
+		c.Skip("Rollover requires ES 5.x, skipping this test")
+	}
+	for _, index := range []string{first, second} {
+		conn.DeleteIndex(index)
+		defer conn.DeleteIndex(index)
+	}
+
+	_, err := conn.CreateIndex(first, map[string]interface{}{
+		"aliases": map[string]interface{}{alias: map[string]interface{}{}},
+	})
+	c.Assert(err, IsNil)
+
+	d := Document{
+		Index:  alias,
+		Type:   "tweet",
+		ID:     "1",
+		Fields: map[string]interface{}{"user": "foo"},
+	}
+	_, err = conn.Index(d, url.Values{"refresh": []string{"true"}})
+	c.Assert(err, IsNil)
+
+	body := RolloverBody{Conditions: RolloverConditions{MaxDocs: 1}}
+
+	response, err := conn.Rollover(alias, "", body, true, IndexOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(response.DryRun, Equals, true)
+	c.Assert(response.RolledOver, Equals, false)
+	c.Assert(response.OldIndex, Equals, first)
+	c.Assert(response.NewIndex, Equals, second)
+	c.Assert(response.Conditions, DeepEquals, map[string]bool{"[max_docs: 1]": true})
+
+	response, err = conn.Rollover(alias, "", body, false, IndexOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(response.RolledOver, Equals, true)
+
+	indexes, err := conn.ResolveAlias(alias)
+	c.Assert(err, IsNil)
+	c.Assert(indexes, DeepEquals, []string{second})
+}
+
+func (s *GoesTestSuite) TestRolloverRequest(c *C) {
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/logs/_rollover/logs-2")
+		c.Check(r.URL.Query().Get("dry_run"), Equals, "true")
+		c.Check(r.URL.Query().Get("wait_for_active_shards"), Equals, "2")
+		c.Check(r.URL.Query().Get("timeout"), Equals, "1m")
+		c.Check(r.URL.Query().Get("error_trace"), Equals, "true")
+
+		var body map[string]interface{}
+		data, _ := ioutil.ReadAll(r.Body)
+		c.Check(json.Unmarshal(data, &body), IsNil)
+		c.Check(body, DeepEquals, map[string]interface{}{
+			"conditions": map[string]interface{}{"max_age": "7d", "max_size": "5gb"},
+			"settings":   map[string]interface{}{"number_of_shards": 2.0},
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"acknowledged": false, "shards_acknowledged": false, "old_index": "logs-1",
+			"new_index": "logs-2", "rolled_over": false, "dry_run": true,
+			"conditions": {"[max_age: 7d]": false, "[max_size: 5gb]": true}}`))
+	})
+	defer ts.Close()
+
+	extraArgs := url.Values{"error_trace": []string{"true"}}
+	response, err := conn.Rollover("logs", "logs-2", RolloverBody{
+		Conditions: RolloverConditions{MaxAge: "7d", MaxSize: "5gb"},
+		TemplateBody: TemplateBody{
+			Settings: map[string]interface{}{"number_of_shards": 2},
+		},
+	}, true, IndexOptions{WaitForActiveShards: "2", Timeout: "1m", ExtraArgs: extraArgs})
+	c.Assert(err, IsNil)
+	c.Assert(extraArgs, HasLen, 1)
+	c.Assert(response, DeepEquals, &RolloverResponse{
+		OldIndex: "logs-1",
+		NewIndex: "logs-2",
+		DryRun:   true,
+		Conditions: map[string]bool{
+			"[max_age: 7d]":   false,
+			"[max_size: 5gb]": true,
+		},
+	})
+}
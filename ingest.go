@@ -0,0 +1,125 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+)
+
+// ErrUpdatePipeline is returned by BulkSend for an update command of a
+// document with a Pipeline, as updated documents do not go through ingest
+// pipelines. Update runs them through their pipeline instead.
+var ErrUpdatePipeline = errors.New("Ingest pipelines are not supported by the update API")
+
+// Pipeline holds an ingest pipeline, the processors documents go through
+// before being indexed
+type Pipeline struct {
+	Description string                   `json:"description,omitempty"`
+	Processors  []map[string]interface{} `json:"processors"`
+	OnFailure   []map[string]interface{} `json:"on_failure,omitempty"`
+	Version     int                      `json:"version,omitempty"`
+}
+
+// SimulateDoc is a sample document fed to SimulatePipeline
+type SimulateDoc struct {
+	Index  string      `json:"_index,omitempty"`
+	Type   string      `json:"_type,omitempty"`
+	ID     string      `json:"_id,omitempty"`
+	Source interface{} `json:"_source"`
+}
+
+// SimulatedDocument is a document as it comes out of a pipeline, or out of
+// one of its processors
+type SimulatedDocument struct {
+	Index  string                 `json:"_index"`
+	Type   string                 `json:"_type"`
+	ID     string                 `json:"_id"`
+	Source map[string]interface{} `json:"_source"`
+	Ingest map[string]interface{} `json:"_ingest"`
+}
+
+// SimulatedProcessorResult holds the outcome of a processor in a verbose
+// simulation. ProcessorType and Status are only reported since ES 7.9.
+type SimulatedProcessorResult struct {
+	ProcessorType string             `json:"processor_type"`
+	Tag           string             `json:"tag"`
+	Status        string             `json:"status"`
+	Doc           *SimulatedDocument `json:"doc"`
+	Error         json.RawMessage    `json:"error"`
+}
+
+// SimulatedPipelineResult holds the outcome of a sample document. Doc or
+// Error is set by a plain simulation, ProcessorResults by a verbose one.
+type SimulatedPipelineResult struct {
+	Doc              *SimulatedDocument         `json:"doc"`
+	Error            json.RawMessage            `json:"error"`
+	ProcessorResults []SimulatedProcessorResult `json:"processor_results"`
+}
+
+// PutPipeline creates or replaces an ingest pipeline
+func (c *Client) PutPipeline(id string, pipeline Pipeline) (*Response, error) {
+	r := Request{
+		Query:  pipeline,
+		Method: "PUT",
+		API:    "_ingest/pipeline/" + id,
+	}
+
+	return c.Do(&r)
+}
+
+// GetPipeline returns the ingest pipelines by id, all of them if no id is
+// given
+func (c *Client) GetPipeline(ids []string) (map[string]Pipeline, error) {
+	r := Request{
+		Method: "GET",
+		API:    namedAPI("_ingest/pipeline", ids),
+	}
+
+	pipelines := map[string]Pipeline{}
+	return pipelines, c.doInto(&r, &pipelines)
+}
+
+// DeletePipeline deletes an ingest pipeline
+func (c *Client) DeletePipeline(id string) (*Response, error) {
+	r := Request{
+		Method: "DELETE",
+		API:    "_ingest/pipeline/" + id,
+	}
+
+	return c.Do(&r)
+}
+
+// SimulatePipeline runs sample documents through a pipeline without indexing
+// them. The pipeline is either the stored one with the given id, or pipeline
+// itself when id is empty. With verbose, the document is returned after each
+// processor.
+func (c *Client) SimulatePipeline(id string, pipeline *Pipeline, docs []SimulateDoc, verbose bool) ([]SimulatedPipelineResult, error) {
+	body := map[string]interface{}{"docs": docs}
+	api := "_ingest/pipeline/_simulate"
+	if id != "" {
+		api = "_ingest/pipeline/" + id + "/_simulate"
+	} else {
+		body["pipeline"] = pipeline
+	}
+
+	r := Request{
+		Query:     body,
+		Method:    "POST",
+		API:       api,
+		ExtraArgs: url.Values{"verbose": []string{strconv.FormatBool(verbose)}},
+	}
+
+	var resp struct {
+		Docs []SimulatedPipelineResult `json:"docs"`
+	}
+	if err := c.doInto(&r, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Docs, nil
+}
@@ -0,0 +1,230 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	. "github.com/go-check/check"
+)
+
+func (s *GoesTestSuite) TestPipeline(c *C) {
+	indexName := "testpipeline"
+	docType := "tweet"
+	pipelineID := "testpipeline"
+
+	conn := NewClient(ESHost, ESPort)
+	if supported, _ := conn.versionAtLeast("5"); !supported {
+		c.Skip("Ingest pipelines require ES 5, skipping this test")
+	}
+	conn.DeleteIndex(indexName)
+	conn.DeletePipeline(pipelineID)
+
+	pipeline := Pipeline{
+		Description: "tags tweets",
+		Processors: []map[string]interface{}{
+			{"set": map[string]interface{}{"field": "source", "value": "goes"}},
+			{"uppercase": map[string]interface{}{"field": "user"}},
+		},
+	}
+	_, err := conn.PutPipeline(pipelineID, pipeline)
+	c.Assert(err, IsNil)
+	defer conn.DeletePipeline(pipelineID)
+
+	pipelines, err := conn.GetPipeline([]string{pipelineID})
+	c.Assert(err, IsNil)
+	c.Assert(pipelines, HasLen, 1)
+	c.Assert(pipelines[pipelineID].Description, Equals, "tags tweets")
+	c.Assert(pipelines[pipelineID].Processors, HasLen, 2)
+
+	_, err = conn.CreateIndex(indexName, map[string]interface{}{})
+	c.Assert(err, IsNil)
+	defer conn.DeleteIndex(indexName)
+
+	_, err = conn.Index(Document{
+		Index:    indexName,
+		Type:     docType,
+		ID:       "1",
+		Fields:   map[string]interface{}{"user": "foo"},
+		Pipeline: pipelineID,
+	}, url.Values{})
+	c.Assert(err, IsNil)
+
+	_, err = conn.BulkSend([]Document{{
+		Index:       indexName,
+		Type:        docType,
+		ID:          "2",
+		BulkCommand: BulkCommandIndex,
+		Fields:      map[string]interface{}{"user": "bar"},
+		Pipeline:    pipelineID,
+	}})
+	c.Assert(err, IsNil)
+
+	_, err = conn.Update(Document{
+		Index:    indexName,
+		Type:     docType,
+		ID:       "2",
+		Pipeline: pipelineID,
+	}, map[string]interface{}{"doc": map[string]interface{}{"user": "baz"}}, url.Values{})
+	c.Assert(err, IsNil)
+
+	for id, user := range map[string]string{"1": "FOO", "2": "BAZ"} {
+		doc, err := conn.Get(indexName, docType, id, url.Values{})
+		c.Assert(err, IsNil)
+		c.Assert(doc.Source, DeepEquals, map[string]interface{}{"user": user, "source": "goes"})
+	}
+
+	results, err := conn.SimulatePipeline(pipelineID, nil, []SimulateDoc{
+		{Source: map[string]interface{}{"user": "baz"}},
+	}, false)
+	c.Assert(err, IsNil)
+	c.Assert(results, HasLen, 1)
+	c.Assert(results[0].Error, IsNil)
+	c.Assert(results[0].Doc.Source, DeepEquals, map[string]interface{}{"user": "BAZ", "source": "goes"})
+
+	results, err = conn.SimulatePipeline("", &pipeline, []SimulateDoc{
+		{Source: map[string]interface{}{"user": "baz"}},
+		{Source: map[string]interface{}{"name": "baz"}},
+	}, true)
+	c.Assert(err, IsNil)
+	c.Assert(results, HasLen, 2)
+	c.Assert(results[0].ProcessorResults, HasLen, 2)
+	c.Assert(results[0].ProcessorResults[0].Doc.Source, DeepEquals, map[string]interface{}{"user": "baz", "source": "goes"})
+	c.Assert(results[0].ProcessorResults[1].Doc.Source, DeepEquals, map[string]interface{}{"user": "BAZ", "source": "goes"})
+	c.Assert(results[1].ProcessorResults[1].Error, NotNil)
+
+	_, err = conn.DeletePipeline(pipelineID)
+	c.Assert(err, IsNil)
+
+	_, err = conn.GetPipeline([]string{pipelineID})
+	c.Assert(err, ErrorMatches, `\[404\] .*`)
+}
+
+func (s *GoesTestSuite) TestSimulatePipelineRequest(c *C) {
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "POST")
+		c.Check(r.URL.Path, Equals, "/_ingest/pipeline/_simulate")
+		c.Check(r.URL.Query().Get("verbose"), Equals, "false")
+
+		var body map[string]interface{}
+		c.Check(json.NewDecoder(r.Body).Decode(&body), IsNil)
+		c.Check(body, DeepEquals, map[string]interface{}{
+			"pipeline": map[string]interface{}{
+				"processors": []interface{}{
+					map[string]interface{}{"lowercase": map[string]interface{}{"field": "user"}},
+				},
+			},
+			"docs": []interface{}{
+				map[string]interface{}{"_id": "1", "_source": map[string]interface{}{"user": "FOO"}},
+				map[string]interface{}{"_source": map[string]interface{}{"name": "bar"}},
+			},
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"docs": [
+			{"doc": {"_index": "_index", "_id": "1", "_source": {"user": "foo"}, "_ingest": {"timestamp": "2020-01-01T00:00:00Z"}}},
+			{"error": {"type": "illegal_argument_exception", "reason": "field [user] not present"}}
+		]}`))
+	})
+	defer ts.Close()
+
+	pipeline := Pipeline{Processors: []map[string]interface{}{
+		{"lowercase": map[string]interface{}{"field": "user"}},
+	}}
+	results, err := conn.SimulatePipeline("", &pipeline, []SimulateDoc{
+		{ID: "1", Source: map[string]interface{}{"user": "FOO"}},
+		{Source: map[string]interface{}{"name": "bar"}},
+	}, false)
+	c.Assert(err, IsNil)
+	c.Assert(results, HasLen, 2)
+	c.Assert(results[0].Doc.ID, Equals, "1")
+	c.Assert(results[0].Doc.Source, DeepEquals, map[string]interface{}{"user": "foo"})
+	c.Assert(results[0].Doc.Ingest["timestamp"], Equals, "2020-01-01T00:00:00Z")
+	c.Assert(results[0].Error, IsNil)
+	c.Assert(results[1].Doc, IsNil)
+	c.Assert(string(results[1].Error), Matches, `.*field \[user\] not present.*`)
+}
+
+func (s *GoesTestSuite) TestPipelineArgs(c *C) {
+	var paths []string
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.RequestURI())
+		if r.URL.Path == "/_bulk" {
+			body, _ := ioutil.ReadAll(r.Body)
+			lines := strings.Split(string(body), "\n")
+			c.Check(lines[0], Equals, `{"index":{"_id":"1","_index":"i","_type":"t","pipeline":"p"}}`)
+			c.Check(lines[2], Equals, `{"delete":{"_id":"2","_index":"i","_type":"t"}}`)
+			c.Check(lines[3], Equals, `{"create":{"_id":"3","_index":"i","_type":"t","pipeline":"p"}}`)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	})
+	defer ts.Close()
+
+	extraArgs := url.Values{"refresh": []string{"true"}}
+	_, err := conn.Index(Document{Index: "i", Type: "t", ID: "1", Fields: map[string]interface{}{}, Pipeline: "p"}, extraArgs)
+	c.Assert(err, IsNil)
+	c.Assert(extraArgs, HasLen, 1)
+
+	_, err = conn.BulkSend([]Document{
+		{Index: "i", Type: "t", ID: "1", BulkCommand: BulkCommandIndex, Fields: map[string]interface{}{"a": 1}, Pipeline: "p"},
+		{Index: "i", Type: "t", ID: "2", BulkCommand: BulkCommandDelete, Pipeline: "p"},
+		{Index: "i", Type: "t", ID: "3", BulkCommand: BulkCommandCreate, Fields: map[string]interface{}{"a": 3}, Pipeline: "p"},
+	})
+	c.Assert(err, IsNil)
+
+	_, err = conn.BulkSend([]Document{
+		{Index: "i", Type: "t", ID: "1", BulkCommand: BulkCommandUpdate, Fields: map[string]interface{}{"doc": map[string]interface{}{}}, Pipeline: "p"},
+	})
+	c.Assert(err, Equals, ErrUpdatePipeline)
+
+	c.Assert(paths, DeepEquals, []string{"/i/t/1/?pipeline=p&refresh=true", "/_bulk"})
+}
+
+func (s *GoesTestSuite) TestUpdatePipeline(c *C) {
+	var requests []string
+	ubqResponse := `{"total": 1, "updated": 1, "failures": []}`
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.URL.RequestURI()+" "+string(body))
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/_update_by_query") {
+			w.Write([]byte(ubqResponse))
+			return
+		}
+		w.Write([]byte(`{"_id": "1", "_version": 2, "result": "updated"}`))
+	})
+	defer ts.Close()
+
+	query := map[string]interface{}{"doc": map[string]interface{}{"user": "FOO"}}
+	extraArgs := url.Values{"routing": []string{"r"}}
+	response, err := conn.Update(Document{Index: "i", Type: "t", ID: "1", Pipeline: "p"}, query, extraArgs)
+	c.Assert(err, IsNil)
+	c.Assert(response.Version, Equals, 2)
+	c.Assert(extraArgs, HasLen, 1)
+
+	_, err = conn.Update(Document{Index: "i", Type: "t", ID: "2", Pipeline: "p"}, query, url.Values{"refresh": []string{"true"}})
+	c.Assert(err, IsNil)
+
+	ubqResponse = `{"total": 1, "updated": 0, "failures": [{"id": "3", "cause": {"type": "exception"}}]}`
+	_, err = conn.Update(Document{Index: "i", Type: "t", ID: "3", Pipeline: "p"}, query, url.Values{})
+	c.Assert(err, ErrorMatches, `Pipeline p failed for document 3: {"id": "3", .*}`)
+
+	ubqResponse = `{"total": 0, "updated": 0, "failures": []}`
+	_, err = conn.Update(Document{Index: "i", Type: "t", ID: "4", Pipeline: "p"}, query, url.Values{})
+	c.Assert(err, ErrorMatches, "Pipeline p did not run on document 4")
+
+	update := `{"doc":{"user":"FOO"}}`
+	c.Assert(requests[:4], DeepEquals, []string{
+		`/i/t/1/_update?refresh=wait_for&routing=r ` + update,
+		`/i/t/_update_by_query?pipeline=p&routing=r {"query":{"ids":{"values":["1"]}}}`,
+		`/i/t/2/_update?refresh=true ` + update,
+		`/i/t/_update_by_query?pipeline=p&refresh=true {"query":{"ids":{"values":["2"]}}}`,
+	})
+}
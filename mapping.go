@@ -0,0 +1,270 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Mapping holds the properties of a mapping. It can be passed as is to
+// PutMapping, or under the "mappings" key of the body of CreateIndex.
+type Mapping struct {
+	Properties map[string]MappingProperty `json:"properties"`
+}
+
+// MappingProperty holds the mapping of a field. Type is empty for objects,
+// which only have Properties. Parameters holds the other parameters of the
+// field, e.g. analyzer or format, decoded as by encoding/json.
+type MappingProperty struct {
+	Type       string
+	Properties map[string]MappingProperty
+	Parameters map[string]interface{}
+}
+
+// MarshalJSON encodes the property as found in a mapping
+func (p MappingProperty) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]interface{}, len(p.Parameters)+2)
+	for k, v := range p.Parameters {
+		fields[k] = v
+	}
+	if p.Type != "" {
+		fields["type"] = p.Type
+	}
+	if p.Properties != nil {
+		fields["properties"] = p.Properties
+	}
+
+	return json.Marshal(fields)
+}
+
+// UnmarshalJSON decodes the property as returned by GetMapping
+func (p *MappingProperty) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	*p = MappingProperty{}
+	for k, raw := range fields {
+		var err error
+		switch k {
+		case "type":
+			err = json.Unmarshal(raw, &p.Type)
+		case "properties":
+			err = json.Unmarshal(raw, &p.Properties)
+		default:
+			if p.Parameters == nil {
+				p.Parameters = map[string]interface{}{}
+			}
+			var v interface{}
+			err = json.Unmarshal(raw, &v)
+			p.Parameters[k] = v
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// MappingFor generates the mapping of the documents encoded by encoding/json
+// from v, a struct or a pointer to a struct.
+//
+// Fields are named after their json tag and their type is derived from their
+// Go type: string is text, bool is boolean, integers are long, integer,
+// short or byte, floats are double or float, time.Time is date, []byte is
+// binary, maps are object and structs are objects with properties. Slices and
+// pointers are mapped as their element. Fields of embedded structs are
+// promoted as with encoding/json and interface fields are left to dynamic
+// mapping.
+//
+// The es tag overrides the type and adds parameters, e.g. es:"keyword",
+// es:"text,analyzer=english", es:"date,format=epoch_millis" or
+// es:",index=false" to only add a parameter. es:"nested" maps a struct as
+// nested documents. Fields tagged es:"-" are not mapped.
+func MappingFor(v interface{}) (*Mapping, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Cannot generate a mapping for %v, a struct is expected", t)
+	}
+
+	properties, err := structProperties(t, map[reflect.Type]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Mapping{Properties: properties}, nil
+}
+
+// structProperties returns the properties of the fields of t. visiting holds
+// the structs being mapped, to detect recursive types.
+func structProperties(t reflect.Type, visiting map[reflect.Type]bool) (map[string]MappingProperty, error) {
+	if visiting[t] {
+		return nil, fmt.Errorf("Cannot generate a mapping for the recursive type %v", t)
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	properties := map[string]MappingProperty{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		name, skip := jsonFieldName(field)
+		esTag := field.Tag.Get("es")
+		if skip || esTag == "-" {
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			embedded, err := structProperties(fieldType, visiting)
+			if err != nil {
+				return nil, err
+			}
+			for k, p := range embedded {
+				// Like encoding/json, fields of the outer struct win
+				if _, ok := properties[k]; !ok {
+					properties[k] = p
+				}
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property, ok, err := fieldProperty(fieldType, esTag, visiting)
+		if err != nil {
+			return nil, fmt.Errorf("Field %s: %s", field.Name, err)
+		}
+		if ok {
+			properties[name] = property
+		}
+	}
+
+	return properties, nil
+}
+
+// jsonFieldName returns the name given to field by its json tag, and whether
+// encoding/json ignores it
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	return strings.Split(tag, ",")[0], false
+}
+
+// fieldProperty returns the property of a field of type t with the es tag
+// esTag. ok is false if the field is left to dynamic mapping.
+func fieldProperty(t reflect.Type, esTag string, visiting map[reflect.Type]bool) (property MappingProperty, ok bool, err error) {
+	options := strings.Split(esTag, ",")
+	property.Type = options[0]
+	for _, option := range options[1:] {
+		parts := strings.SplitN(option, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return property, false, fmt.Errorf("Invalid es tag option %q", option)
+		}
+		if property.Parameters == nil {
+			property.Parameters = map[string]interface{}{}
+		}
+		property.Parameters[parts[0]] = tagValue(parts[1])
+	}
+
+	// Slices and arrays are mapped as their elements, except []byte which
+	// encoding/json encodes as a base64 string
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Array ||
+		(t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8) {
+		t = t.Elem()
+	}
+
+	switch property.Type {
+	case "":
+		property.Type = defaultType(t)
+		if property.Type == "" && t.Kind() != reflect.Struct {
+			return property, false, nil
+		}
+	case "object", "nested":
+	default:
+		return property, true, nil
+	}
+
+	if t.Kind() == reflect.Struct && t != timeType {
+		if property.Type == "object" {
+			// Objects are returned without type by GetMapping
+			property.Type = ""
+		}
+		property.Properties, err = structProperties(t, visiting)
+	}
+
+	return property, true, err
+}
+
+// defaultType returns the field type of t, an empty string for structs and
+// for types left to dynamic mapping
+func defaultType(t reflect.Type) string {
+	if t == timeType {
+		return "date"
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "text"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int8:
+		return "byte"
+	case reflect.Int16, reflect.Uint8:
+		return "short"
+	case reflect.Int32, reflect.Uint16:
+		return "integer"
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return "long"
+	case reflect.Float32:
+		return "float"
+	case reflect.Float64:
+		return "double"
+	case reflect.Slice:
+		// Only []byte is left by fieldProperty
+		return "binary"
+	case reflect.Map:
+		return "object"
+	}
+
+	return ""
+}
+
+// tagValue decodes the value of an es tag option as encoding/json would
+// decode it from a mapping: booleans and numbers are not kept as strings
+func tagValue(s string) interface{} {
+	if s == "true" || s == "false" {
+		return s == "true"
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return s
+}
@@ -0,0 +1,169 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/go-check/check"
+)
+
+type mappingTestAuthor struct {
+	Name  string   `json:"name" es:"keyword"`
+	Email *string  `json:"email,omitempty" es:"keyword,index=false"`
+	Tags  []string `json:"tags" es:"keyword,ignore_above=256"`
+}
+
+type mappingTestMeta struct {
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt int64     `json:"updated_at" es:"date,format=epoch_millis"`
+	Title     string    `json:"meta_title"`
+}
+
+type mappingTestComment struct {
+	Author mappingTestAuthor `json:"author"`
+	Body   string            `json:"body" es:"text,analyzer=english"`
+}
+
+type mappingTestPost struct {
+	mappingTestMeta
+	ID       string               `json:"id" es:"keyword"`
+	Title    string               `json:"title" es:"text,analyzer=english"`
+	Views    int                  `json:"views"`
+	Rank     int16                `json:"rank"`
+	Score    float64              `json:"score"`
+	Ratio    float32              `json:"ratio"`
+	Public   bool                 `json:"public"`
+	Author   *mappingTestAuthor   `json:"author"`
+	Comments []mappingTestComment `json:"comments" es:"nested"`
+	Labels   map[string]string    `json:"labels"`
+	Avatar   []byte               `json:"avatar"`
+	Dates    []*time.Time         `json:"dates"`
+	Extra    interface{}          `json:"extra"`
+	Secret   string               `json:"-"`
+	Internal string               `json:"internal" es:"-"`
+	NoTag    string
+	private  string
+}
+
+type mappingTestTree struct {
+	Name     string            `json:"name"`
+	Children []mappingTestTree `json:"children"`
+}
+
+func (s *GoesTestSuite) TestMappingFor(c *C) {
+	mapping, err := MappingFor(&mappingTestPost{})
+	c.Assert(err, IsNil)
+
+	author := MappingProperty{Properties: map[string]MappingProperty{
+		"name":  {Type: "keyword"},
+		"email": {Type: "keyword", Parameters: map[string]interface{}{"index": false}},
+		"tags":  {Type: "keyword", Parameters: map[string]interface{}{"ignore_above": float64(256)}},
+	}}
+	english := map[string]interface{}{"analyzer": "english"}
+
+	c.Assert(mapping.Properties, DeepEquals, map[string]MappingProperty{
+		"created_at": {Type: "date"},
+		"updated_at": {Type: "date", Parameters: map[string]interface{}{"format": "epoch_millis"}},
+		"meta_title": {Type: "text"},
+		"id":         {Type: "keyword"},
+		"title":      {Type: "text", Parameters: english},
+		"views":      {Type: "long"},
+		"rank":       {Type: "short"},
+		"score":      {Type: "double"},
+		"ratio":      {Type: "float"},
+		"public":     {Type: "boolean"},
+		"author":     author,
+		"comments": {Type: "nested", Properties: map[string]MappingProperty{
+			"author": author,
+			"body":   {Type: "text", Parameters: english},
+		}},
+		"labels": {Type: "object"},
+		"avatar": {Type: "binary"},
+		"dates":  {Type: "date"},
+		"NoTag":  {Type: "text"},
+	})
+}
+
+func (s *GoesTestSuite) TestMappingForErrors(c *C) {
+	_, err := MappingFor("foo")
+	c.Assert(err, ErrorMatches, "Cannot generate a mapping for string, a struct is expected")
+
+	_, err = MappingFor(nil)
+	c.Assert(err, ErrorMatches, "Cannot generate a mapping for <nil>, a struct is expected")
+
+	_, err = MappingFor(mappingTestTree{})
+	c.Assert(err, ErrorMatches, "Field Children: Cannot generate a mapping for the recursive type goes.mappingTestTree")
+
+	_, err = MappingFor(struct {
+		Name string `es:"text,analyzer"`
+	}{})
+	c.Assert(err, ErrorMatches, `Field Name: Invalid es tag option "analyzer"`)
+}
+
+func (s *GoesTestSuite) TestMappingJSON(c *C) {
+	mapping, err := MappingFor(mappingTestComment{})
+	c.Assert(err, IsNil)
+
+	data, err := json.Marshal(mapping)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, `{"properties":{`+
+		`"author":{"properties":{`+
+		`"email":{"index":false,"type":"keyword"},`+
+		`"name":{"type":"keyword"},`+
+		`"tags":{"ignore_above":256,"type":"keyword"}}},`+
+		`"body":{"analyzer":"english","type":"text"}}}`)
+
+	decoded := &Mapping{}
+	c.Assert(json.Unmarshal(data, decoded), IsNil)
+	c.Assert(decoded, DeepEquals, mapping)
+}
+
+func (s *GoesTestSuite) TestMappingForPutMapping(c *C) {
+	indexName := "testmappingfor"
+	docType := "post"
+
+	conn := NewClient(ESHost, ESPort)
+	if supported, _ := conn.versionAtLeast("5"); !supported {
+		c.Skip("Keyword fields require ES 5, skipping this test")
+	}
+	conn.DeleteIndex(indexName)
+
+	_, err := conn.CreateIndex(indexName, map[string]interface{}{})
+	c.Assert(err, IsNil)
+	defer conn.DeleteIndex(indexName)
+
+	mapping, err := MappingFor(mappingTestPost{})
+	c.Assert(err, IsNil)
+
+	_, err = conn.PutMapping(docType, mapping, []string{indexName})
+	c.Assert(err, IsNil)
+
+	resp, err := conn.GetMapping([]string{docType}, []string{indexName})
+	c.Assert(err, IsNil)
+
+	data, err := json.Marshal(resp.Raw[indexName])
+	c.Assert(err, IsNil)
+
+	var indexMapping struct {
+		Mappings map[string]json.RawMessage `json:"mappings"`
+	}
+	c.Assert(json.Unmarshal(data, &indexMapping), IsNil)
+
+	raw, ok := indexMapping.Mappings[docType]
+	if !ok {
+		// Typeless mappings, as returned since ES 7
+		raw, err = json.Marshal(indexMapping.Mappings)
+		c.Assert(err, IsNil)
+	}
+
+	stored := &Mapping{}
+	c.Assert(json.Unmarshal(raw, stored), IsNil)
+	c.Assert(stored.Properties["id"], DeepEquals, MappingProperty{Type: "keyword"})
+	c.Assert(stored.Properties["comments"].Type, Equals, "nested")
+	c.Assert(stored.Properties["author"].Properties["email"], DeepEquals, mapping.Properties["author"].Properties["email"])
+	c.Assert(stored.Properties["updated_at"], DeepEquals, mapping.Properties["updated_at"])
+}
@@ -0,0 +1,440 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// MappingChangeAdded is the kind of change of a field added to a mapping
+	MappingChangeAdded = "added"
+	// MappingChangeRemoved is the kind of change of a field removed from a
+	// mapping
+	MappingChangeRemoved = "removed"
+	// MappingChangeType is the kind of change of a field whose type changed
+	MappingChangeType = "type"
+	// MappingChangeParameter is the kind of change of a field whose
+	// parameter changed
+	MappingChangeParameter = "parameter"
+)
+
+// updatableParameters lists the mapping parameters PutMapping can change on
+// an existing field
+var updatableParameters = map[string]bool{
+	"copy_to":               true,
+	"dynamic":               true,
+	"eager_global_ordinals": true,
+	"ignore_above":          true,
+	"ignore_malformed":      true,
+	"meta":                  true,
+	"search_analyzer":       true,
+	"search_quote_analyzer": true,
+}
+
+// MappingChange describes a difference between two mappings
+type MappingChange struct {
+	// Index whose mapping changes, only set by PlanMappingMigration
+	Index string
+
+	// Field is the dotted path of the field, e.g. author.name for a
+	// property of an object or title.raw for a multi-field
+	Field string
+
+	// Kind is one of the MappingChange* constants
+	Kind string
+
+	// Parameter is the name of the changed parameter for MappingChangeParameter
+	Parameter string
+
+	// Old and New hold the properties of the field for MappingChangeAdded
+	// and MappingChangeRemoved, its types for MappingChangeType and the
+	// values of its parameter for MappingChangeParameter
+	Old interface{}
+	New interface{}
+
+	// Breaking is set when the change can not be applied with PutMapping,
+	// the documents have to be reindexed in a new index
+	Breaking bool
+}
+
+func (ch MappingChange) String() string {
+	field := ch.Field
+	if ch.Index != "" {
+		field = ch.Index + ": " + field
+	}
+	impact := "additive"
+	switch {
+	case ch.Breaking:
+		impact = "breaking"
+	case ch.Kind == MappingChangeRemoved:
+		impact = "kept"
+	}
+
+	switch ch.Kind {
+	case MappingChangeType:
+		return fmt.Sprintf("%s: type changed from %v to %v (%s)", field, ch.Old, ch.New, impact)
+	case MappingChangeParameter:
+		return fmt.Sprintf("%s: %s changed from %s to %s (%s)", field, ch.Parameter, jsonString(ch.Old), jsonString(ch.New), impact)
+	}
+	return fmt.Sprintf("%s: %s (%s)", field, ch.Kind, impact)
+}
+
+// DiffMappings compares the current mapping of an index with the desired
+// one and returns their differences, sorted by field. Fields are added
+// without breaking, but they can not change type. Removed fields are not
+// breaking either: PutMapping keeps them, as it keeps the fields added by
+// dynamic mapping, so they are only reported. Only some parameters, e.g.
+// ignore_above or search_analyzer, can be changed. Parameters must be set
+// alike in both mappings to be seen as equal, a default value is not equal
+// to a missing parameter.
+func DiffMappings(current *Mapping, desired *Mapping) []MappingChange {
+	var changes []MappingChange
+	diffProperties("", current.Properties, desired.Properties, &changes)
+	return changes
+}
+
+func diffProperties(prefix string, current map[string]MappingProperty, desired map[string]MappingProperty, changes *[]MappingChange) {
+	names := make([]string, 0, len(current)+len(desired))
+	for name := range current {
+		names = append(names, name)
+	}
+	for name := range desired {
+		if _, ok := current[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		field := prefix + name
+		cur, inCurrent := current[name]
+		des, inDesired := desired[name]
+
+		switch {
+		case !inCurrent:
+			*changes = append(*changes, MappingChange{Field: field, Kind: MappingChangeAdded, New: des})
+		case !inDesired:
+			*changes = append(*changes, MappingChange{Field: field, Kind: MappingChangeRemoved, Old: cur})
+		default:
+			diffProperty(field, cur, des, changes)
+		}
+	}
+}
+
+func diffProperty(field string, current MappingProperty, desired MappingProperty, changes *[]MappingChange) {
+	if objectType(current.Type) != objectType(desired.Type) {
+		*changes = append(*changes, MappingChange{
+			Field:    field,
+			Kind:     MappingChangeType,
+			Old:      current.Type,
+			New:      desired.Type,
+			Breaking: true,
+		})
+		return
+	}
+
+	parameters := make([]string, 0, len(current.Parameters)+len(desired.Parameters))
+	for parameter := range current.Parameters {
+		parameters = append(parameters, parameter)
+	}
+	for parameter := range desired.Parameters {
+		if _, ok := current.Parameters[parameter]; !ok {
+			parameters = append(parameters, parameter)
+		}
+	}
+	sort.Strings(parameters)
+
+	for _, parameter := range parameters {
+		oldValue, newValue := current.Parameters[parameter], desired.Parameters[parameter]
+		if parameter == "fields" {
+			diffProperties(field+".", multiFields(oldValue), multiFields(newValue), changes)
+			continue
+		}
+		if jsonString(oldValue) == jsonString(newValue) {
+			continue
+		}
+
+		*changes = append(*changes, MappingChange{
+			Field:     field,
+			Kind:      MappingChangeParameter,
+			Parameter: parameter,
+			Old:       oldValue,
+			New:       newValue,
+			Breaking:  !parameterUpdatable(parameter, newValue),
+		})
+	}
+
+	diffProperties(field+".", current.Properties, desired.Properties, changes)
+}
+
+// objectType returns the type of a field, objects being returned without
+// type by GetMapping
+func objectType(fieldType string) string {
+	if fieldType == "object" {
+		return ""
+	}
+	return fieldType
+}
+
+// parameterUpdatable tells whether PutMapping can set parameter to value on
+// an existing field
+func parameterUpdatable(parameter string, value interface{}) bool {
+	switch parameter {
+	case "norms":
+		// Norms can be disabled, not enabled
+		return value == false
+	case "fielddata":
+		// Fielddata can be enabled, not disabled
+		return value == true
+	}
+	return updatableParameters[parameter]
+}
+
+// multiFields decodes the fields parameter of a property
+func multiFields(fields interface{}) map[string]MappingProperty {
+	if fields == nil {
+		return nil
+	}
+
+	properties := map[string]MappingProperty{}
+	if data, err := json.Marshal(fields); err == nil {
+		json.Unmarshal(data, &properties)
+	}
+	return properties
+}
+
+// jsonString returns the JSON encoding of v, in which map keys are sorted
+// so that it can be used to compare values
+func jsonString(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// MappingMigration describes the migration of the indexes behind an alias to
+// a new mapping
+type MappingMigration struct {
+	// Alias whose indexes are migrated, it must be an alias and not the
+	// name of an index. It is created if it does not exist yet.
+	Alias string
+
+	// Mapping is the desired mapping
+	Mapping *Mapping
+
+	// NewIndex is created with Settings and Mapping when the migration
+	// requires a reindex or when the alias does not exist yet
+	NewIndex string
+	Settings map[string]interface{}
+
+	// DeleteOld deletes the indexes previously behind the alias once it
+	// points to NewIndex
+	DeleteOld bool
+
+	// RemoveFields makes the fields missing from Mapping breaking changes,
+	// so that they are dropped by reindexing into NewIndex. Fields added by
+	// dynamic mapping come back with the reindexed documents, Mapping
+	// should then disable dynamic mapping.
+	RemoveFields bool
+}
+
+// MigrationStep is an operation of a migration plan
+type MigrationStep struct {
+	Description string
+	run         func(c *Client) error
+}
+
+// MigrationPlan holds the changes of a mapping migration and the steps to
+// apply them. A plan with no steps has nothing to migrate.
+type MigrationPlan struct {
+	Changes []MappingChange
+
+	// Reindex is set when a change is breaking, the steps then fill a new
+	// index and move the alias to it
+	Reindex bool
+
+	Steps []MigrationStep
+}
+
+// Execute runs the steps of the plan in order, stopping at the first error
+func (p *MigrationPlan) Execute(c *Client) error {
+	for _, step := range p.Steps {
+		if err := step.run(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *MigrationPlan) String() string {
+	var buf bytes.Buffer
+
+	if len(p.Changes) == 0 {
+		buf.WriteString("No mapping changes\n")
+	}
+	for _, change := range p.Changes {
+		fmt.Fprintf(&buf, "%s\n", change)
+	}
+	for i, step := range p.Steps {
+		fmt.Fprintf(&buf, "%d. %s\n", i+1, step.Description)
+	}
+
+	return buf.String()
+}
+
+// PlanMappingMigration compares the live mappings of the indexes behind an
+// alias with the desired mapping and plans their migration. Additive changes
+// are planned as a PutMapping on these indexes, breaking ones as a SwapAlias
+// to NewIndex, reindexing their documents. When NewIndex is needed but not
+// set, the plan is returned without steps along with an error.
+func (c *Client) PlanMappingMigration(migration MappingMigration) (*MigrationPlan, error) {
+	mappings, typeName, err := c.indexMappings(migration.Alias)
+	if searchErr, ok := err.(*SearchError); ok && searchErr.StatusCode == 404 {
+		// The alias does not exist yet
+		mappings, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &MigrationPlan{}
+	indexes := make([]string, 0, len(mappings))
+	for index := range mappings {
+		indexes = append(indexes, index)
+	}
+	sort.Strings(indexes)
+
+	putMapping := false
+	for _, index := range indexes {
+		for _, change := range DiffMappings(mappings[index], migration.Mapping) {
+			change.Index = index
+			if change.Kind == MappingChangeRemoved {
+				change.Breaking = migration.RemoveFields
+			} else {
+				putMapping = true
+			}
+			plan.Changes = append(plan.Changes, change)
+			plan.Reindex = plan.Reindex || change.Breaking
+		}
+	}
+
+	if len(indexes) > 0 && !plan.Reindex {
+		if putMapping {
+			plan.Steps = append(plan.Steps, putMappingStep(indexes, typeName, migration.Mapping))
+		}
+		return plan, nil
+	}
+
+	if migration.NewIndex == "" {
+		return plan, fmt.Errorf("Migrating %s requires a new index, NewIndex must be set", migration.Alias)
+	}
+	plan.Steps = append(plan.Steps, swapAliasStep(migration, indexes, typeName))
+
+	return plan, nil
+}
+
+func putMappingStep(indexes []string, typeName string, mapping *Mapping) MigrationStep {
+	return MigrationStep{
+		Description: fmt.Sprintf("Put the mapping of %s", strings.Join(indexes, ", ")),
+		run: func(c *Client) error {
+			_, err := c.PutMapping(typeName, mapping, indexes)
+			return err
+		},
+	}
+}
+
+func swapAliasStep(migration MappingMigration, oldIndexes []string, typeName string) MigrationStep {
+	var mappings interface{} = migration.Mapping
+	if typeName != "" {
+		mappings = map[string]interface{}{typeName: migration.Mapping}
+	}
+	body := map[string]interface{}{"mappings": mappings}
+	if migration.Settings != nil {
+		body["settings"] = migration.Settings
+	}
+
+	description := fmt.Sprintf("Create %s and point %s to it", migration.NewIndex, migration.Alias)
+	if len(oldIndexes) > 0 {
+		description = fmt.Sprintf("Create %s, reindex %s into it and move %s to it",
+			migration.NewIndex, strings.Join(oldIndexes, ", "), migration.Alias)
+		if migration.DeleteOld {
+			description += ", deleting the old indexes"
+		}
+	}
+
+	return MigrationStep{
+		Description: description,
+		run: func(c *Client) error {
+			_, err := c.SwapAlias(AliasSwap{
+				Alias:     migration.Alias,
+				NewIndex:  migration.NewIndex,
+				Mapping:   body,
+				DeleteOld: migration.DeleteOld,
+			})
+			return err
+		},
+	}
+}
+
+// indexMappings returns the mappings of the indexes matching index, by index
+// name. Before ES 7 mappings are per type, the indexes must then hold a
+// single type, whose name is returned.
+func (c *Client) indexMappings(index string) (map[string]*Mapping, string, error) {
+	typeless, err := c.versionAtLeast("7")
+	if err != nil {
+		return nil, "", err
+	}
+
+	resp, err := c.GetMapping(nil, []string{index})
+	if err != nil {
+		return nil, "", err
+	}
+
+	mappings := make(map[string]*Mapping, len(resp.Raw))
+	var typeName string
+	for name, raw := range resp.Raw {
+		data, err := json.Marshal(raw)
+		if err != nil {
+			return nil, "", err
+		}
+		var body struct {
+			Mappings json.RawMessage `json:"mappings"`
+		}
+		if err := json.Unmarshal(data, &body); err != nil {
+			return nil, "", err
+		}
+
+		mapping := &Mapping{}
+		if typeless {
+			if err := json.Unmarshal(body.Mappings, mapping); err != nil {
+				return nil, "", err
+			}
+		} else {
+			var types map[string]*Mapping
+			if err := json.Unmarshal(body.Mappings, &types); err != nil {
+				return nil, "", err
+			}
+			delete(types, "_default_")
+			if len(types) != 1 {
+				return nil, "", fmt.Errorf("Index %s holds %d mapping types instead of 1", name, len(types))
+			}
+			for t, typeMapping := range types {
+				if typeName != "" && t != typeName {
+					return nil, "", fmt.Errorf("Indexes of %s hold different mapping types, %s and %s", index, typeName, t)
+				}
+				typeName, mapping = t, typeMapping
+			}
+		}
+		mappings[name] = mapping
+	}
+
+	return mappings, typeName, nil
+}
@@ -0,0 +1,255 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	. "github.com/go-check/check"
+)
+
+func decodeTestMapping(c *C, data string) *Mapping {
+	mapping := &Mapping{}
+	c.Assert(json.Unmarshal([]byte(data), mapping), IsNil)
+	return mapping
+}
+
+func (s *GoesTestSuite) TestDiffMappings(c *C) {
+	current := decodeTestMapping(c, `{"properties": {
+		"id": {"type": "keyword"},
+		"title": {"type": "text", "analyzer": "english", "fields": {"raw": {"type": "keyword"}}},
+		"tags": {"type": "keyword", "ignore_above": 128},
+		"views": {"type": "integer"},
+		"author": {"properties": {"name": {"type": "text"}}},
+		"legacy": {"type": "keyword"},
+		"body": {"type": "text", "norms": true}
+	}}`)
+	desired := decodeTestMapping(c, `{"properties": {
+		"id": {"type": "keyword"},
+		"title": {"type": "text", "analyzer": "standard", "fields": {"raw": {"type": "keyword"}, "en": {"type": "text"}}},
+		"tags": {"type": "keyword", "ignore_above": 256},
+		"views": {"type": "long"},
+		"author": {"type": "object", "properties": {"name": {"type": "text"}, "email": {"type": "keyword"}}},
+		"body": {"type": "text", "norms": false},
+		"created_at": {"type": "date"}
+	}}`)
+
+	changes := DiffMappings(current, desired)
+	c.Assert(changes, DeepEquals, []MappingChange{
+		{Field: "author.email", Kind: MappingChangeAdded, New: MappingProperty{Type: "keyword"}},
+		{Field: "body", Kind: MappingChangeParameter, Parameter: "norms", Old: true, New: false},
+		{Field: "created_at", Kind: MappingChangeAdded, New: MappingProperty{Type: "date"}},
+		{Field: "legacy", Kind: MappingChangeRemoved, Old: MappingProperty{Type: "keyword"}},
+		{Field: "tags", Kind: MappingChangeParameter, Parameter: "ignore_above", Old: 128.0, New: 256.0},
+		{Field: "title", Kind: MappingChangeParameter, Parameter: "analyzer", Old: "english", New: "standard", Breaking: true},
+		{Field: "title.en", Kind: MappingChangeAdded, New: MappingProperty{Type: "text"}},
+		{Field: "views", Kind: MappingChangeType, Old: "integer", New: "long", Breaking: true},
+	})
+
+	c.Assert(changes[1].String(), Equals, "body: norms changed from true to false (additive)")
+	c.Assert(changes[3].String(), Equals, "legacy: removed (kept)")
+	c.Assert(changes[7].String(), Equals, "views: type changed from integer to long (breaking)")
+
+	c.Assert(DiffMappings(current, current), IsNil)
+}
+
+func (s *GoesTestSuite) TestPlanMappingMigrationAdditive(c *C) {
+	var putBody map[string]interface{}
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`{"version": {"number": "6.8.0"}}`))
+		case "/posts/_mapping":
+			w.Write([]byte(`{
+				"posts_v2": {"mappings": {"post": {"properties": {"id": {"type": "keyword"}}}}},
+				"posts_v1": {"mappings": {"post": {"properties": {"id": {"type": "keyword"}}}}}
+			}`))
+		case "/posts_v1,posts_v2/_mappings/post":
+			c.Check(r.Method, Equals, "PUT")
+			data, _ := ioutil.ReadAll(r.Body)
+			c.Check(json.Unmarshal(data, &putBody), IsNil)
+			w.Write([]byte(`{"acknowledged": true}`))
+		default:
+			c.Errorf("Unexpected request %s %s", r.Method, r.URL)
+		}
+	})
+	defer ts.Close()
+
+	desired := decodeTestMapping(c, `{"properties": {"id": {"type": "keyword"}, "title": {"type": "text"}}}`)
+	plan, err := conn.PlanMappingMigration(MappingMigration{Alias: "posts", Mapping: desired})
+	c.Assert(err, IsNil)
+	c.Assert(plan.Reindex, Equals, false)
+	c.Assert(plan.Changes, HasLen, 2)
+	c.Assert(plan.Changes[0].Index, Equals, "posts_v1")
+	c.Assert(plan.Changes[1].Index, Equals, "posts_v2")
+	c.Assert(plan.String(), Equals, "posts_v1: title: added (additive)\n"+
+		"posts_v2: title: added (additive)\n"+
+		"1. Put the mapping of posts_v1, posts_v2\n")
+	c.Assert(putBody, IsNil)
+
+	c.Assert(plan.Execute(conn), IsNil)
+	c.Assert(putBody, DeepEquals, map[string]interface{}{"properties": map[string]interface{}{
+		"id":    map[string]interface{}{"type": "keyword"},
+		"title": map[string]interface{}{"type": "text"},
+	}})
+
+	plan, err = conn.PlanMappingMigration(MappingMigration{
+		Alias:   "posts",
+		Mapping: decodeTestMapping(c, `{"properties": {"id": {"type": "keyword"}}}`),
+	})
+	c.Assert(err, IsNil)
+	c.Assert(plan.Steps, HasLen, 0)
+	c.Assert(plan.String(), Equals, "No mapping changes\n")
+
+	// Fields missing from the desired mapping are kept, e.g. dynamic ones
+	removed := MappingMigration{
+		Alias:   "posts",
+		Mapping: decodeTestMapping(c, `{"properties": {}}`),
+	}
+	plan, err = conn.PlanMappingMigration(removed)
+	c.Assert(err, IsNil)
+	c.Assert(plan.Reindex, Equals, false)
+	c.Assert(plan.Steps, HasLen, 0)
+	c.Assert(plan.String(), Equals, "posts_v1: id: removed (kept)\nposts_v2: id: removed (kept)\n")
+
+	removed.RemoveFields = true
+	plan, err = conn.PlanMappingMigration(removed)
+	c.Assert(err, ErrorMatches, "Migrating posts requires a new index, NewIndex must be set")
+	c.Assert(plan.Reindex, Equals, true)
+	c.Assert(plan.Changes[0].String(), Equals, "posts_v1: id: removed (breaking)")
+}
+
+func (s *GoesTestSuite) TestPlanMappingMigrationBreaking(c *C) {
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/" {
+			w.Write([]byte(`{"version": {"number": "7.10.0"}}`))
+			return
+		}
+		c.Check(r.URL.Path, Equals, "/posts/_mapping")
+		w.Write([]byte(`{"posts_v1": {"mappings": {"properties": {"views": {"type": "integer"}}}}}`))
+	})
+	defer ts.Close()
+
+	migration := MappingMigration{
+		Alias:   "posts",
+		Mapping: decodeTestMapping(c, `{"properties": {"views": {"type": "long"}}}`),
+	}
+	plan, err := conn.PlanMappingMigration(migration)
+	c.Assert(err, ErrorMatches, "Migrating posts requires a new index, NewIndex must be set")
+	c.Assert(plan.Reindex, Equals, true)
+	c.Assert(plan.Changes, HasLen, 1)
+	c.Assert(plan.Steps, HasLen, 0)
+
+	migration.NewIndex = "posts_v2"
+	migration.DeleteOld = true
+	plan, err = conn.PlanMappingMigration(migration)
+	c.Assert(err, IsNil)
+	c.Assert(plan.Steps, HasLen, 1)
+	c.Assert(plan.Steps[0].Description, Equals, "Create posts_v2, reindex posts_v1 into it and move posts to it, deleting the old indexes")
+}
+
+func (s *GoesTestSuite) TestPlanMappingMigrationTypes(c *C) {
+	response := ""
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/" {
+			w.Write([]byte(`{"version": {"number": "5.6.0"}}`))
+			return
+		}
+		c.Check(r.URL.Path, Equals, "/posts/_mapping")
+		w.Write([]byte(response))
+	})
+	defer ts.Close()
+
+	migration := MappingMigration{
+		Alias:   "posts",
+		Mapping: decodeTestMapping(c, `{"properties": {"id": {"type": "keyword"}}}`),
+	}
+
+	response = `{"posts_v1": {"mappings": {"_default_": {}, "post": {}, "comment": {}}}}`
+	_, err := conn.PlanMappingMigration(migration)
+	c.Assert(err, ErrorMatches, "Index posts_v1 holds 2 mapping types instead of 1")
+
+	response = `{"posts_v1": {"mappings": {"_default_": {}}}}`
+	_, err = conn.PlanMappingMigration(migration)
+	c.Assert(err, ErrorMatches, "Index posts_v1 holds 0 mapping types instead of 1")
+
+	response = `{"posts_v1": {"mappings": {"post": {}}}, "posts_v2": {"mappings": {"article": {}}}}`
+	_, err = conn.PlanMappingMigration(migration)
+	c.Assert(err, ErrorMatches, "Indexes of posts hold different mapping types, .* and .*")
+
+	response = `{"posts_v1": {"mappings": {"_default_": {}, "post": {"properties": {"id": {"type": "keyword"}}}}}}`
+	plan, err := conn.PlanMappingMigration(migration)
+	c.Assert(err, IsNil)
+	c.Assert(plan.Changes, HasLen, 0)
+	c.Assert(plan.Steps, HasLen, 0)
+}
+
+func (s *GoesTestSuite) TestPlanMappingMigration(c *C) {
+	alias := "testplanmigration"
+	v1 := "testplanmigration_v1"
+	v2 := "testplanmigration_v2"
+	docType := "_doc"
+
+	conn := NewClient(ESHost, ESPort)
+	if supported, _ := conn.versionAtLeast("7"); !supported {
+		c.Skip("Typeless mappings require ES 7, skipping this test")
+	}
+	conn.DeleteIndex(v1)
+	conn.DeleteIndex(v2)
+	defer conn.DeleteIndex(v1)
+	defer conn.DeleteIndex(v2)
+
+	settings := map[string]interface{}{"number_of_shards": 1, "number_of_replicas": 0}
+
+	// The alias does not exist yet, the first index is created
+	plan, err := conn.PlanMappingMigration(MappingMigration{
+		Alias:    alias,
+		Mapping:  decodeTestMapping(c, `{"properties": {"views": {"type": "integer"}}}`),
+		NewIndex: v1,
+		Settings: settings,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(plan.Steps, HasLen, 1)
+	c.Assert(plan.Execute(conn), IsNil)
+
+	_, err = conn.Index(Document{Index: alias, Type: docType, ID: "1", Fields: map[string]interface{}{"views": 3}}, url.Values{"refresh": []string{"true"}})
+	c.Assert(err, IsNil)
+
+	// Adding a field is applied in place
+	plan, err = conn.PlanMappingMigration(MappingMigration{
+		Alias:   alias,
+		Mapping: decodeTestMapping(c, `{"properties": {"views": {"type": "integer"}, "title": {"type": "text"}}}`),
+	})
+	c.Assert(err, IsNil)
+	c.Assert(plan.Reindex, Equals, false)
+	c.Assert(plan.Execute(conn), IsNil)
+
+	// Changing a type requires a reindex
+	desired := decodeTestMapping(c, `{"properties": {"views": {"type": "long"}, "title": {"type": "text"}}}`)
+	plan, err = conn.PlanMappingMigration(MappingMigration{
+		Alias:     alias,
+		Mapping:   desired,
+		NewIndex:  v2,
+		Settings:  settings,
+		DeleteOld: true,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(plan.Reindex, Equals, true)
+	c.Assert(plan.Execute(conn), IsNil)
+
+	indexes, err := conn.ResolveAlias(alias)
+	c.Assert(err, IsNil)
+	c.Assert(indexes, DeepEquals, []string{v2})
+
+	plan, err = conn.PlanMappingMigration(MappingMigration{Alias: alias, Mapping: desired})
+	c.Assert(err, IsNil)
+	c.Assert(plan.Changes, HasLen, 0)
+}
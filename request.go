@@ -3,6 +3,7 @@ package goes
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -96,6 +97,10 @@ func (req *Request) Request() (*http.Request, error) {
 	}
 	newReq.URL = req.URL()
 	newReq.Body = ioutil.NopCloser(bytes.NewReader(postData))
+	// Allows redirects with a 307 or 308 to send the body again
+	newReq.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(postData)), nil
+	}
 	newReq.ContentLength = int64(len(postData))
 
 	if req.Method == "POST" || req.Method == "PUT" {
@@ -0,0 +1,375 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// migrationLockID is the id of the lock document in the history index
+const migrationLockID = "lock"
+
+// ErrMigrationLocked is returned by Migrate when another deployer holds the
+// lock of the history index
+var ErrMigrationLocked = errors.New("Migrations are locked by another deployer")
+
+// Migration is a change of the cluster applied once by a Migrator. Use the
+// *Migration functions for the common changes, or set Up to run any code.
+type Migration struct {
+	// ID identifies the migration in the history index, it must be unique
+	// and must not change once the migration is applied
+	ID          string
+	Description string
+	Up          func(c *Client) error
+}
+
+// MigrationRecord is a migration recorded in the history index
+type MigrationRecord struct {
+	ID             string    `json:"id"`
+	Description    string    `json:"description"`
+	AppliedAt      time.Time `json:"applied_at"`
+	DurationMillis int64     `json:"duration_millis"`
+}
+
+// migrationLock is the lock document of the history index
+type migrationLock struct {
+	Owner      string    `json:"owner"`
+	AcquiredAt time.Time `json:"acquired_at"`
+}
+
+// CreateIndexMigration creates an index, body holds its settings, mappings
+// and aliases as given to CreateIndex
+func CreateIndexMigration(id string, index string, body interface{}) Migration {
+	return Migration{
+		ID:          id,
+		Description: "Create index " + index,
+		Up: func(c *Client) error {
+			_, err := c.CreateIndex(index, body)
+			return err
+		},
+	}
+}
+
+// PutMappingMigration puts a mapping on indexes, typeName being ignored
+// since ES 7
+func PutMappingMigration(id string, typeName string, mapping interface{}, indexes []string) Migration {
+	return Migration{
+		ID:          id,
+		Description: "Put the mapping of " + strings.Join(indexes, ", "),
+		Up: func(c *Client) error {
+			if typeless, err := c.versionAtLeast("7"); err != nil {
+				return err
+			} else if typeless {
+				typeName = ""
+			}
+			_, err := c.PutMapping(typeName, mapping, indexes)
+			return err
+		},
+	}
+}
+
+// AddAliasMigration points an alias to indexes
+func AddAliasMigration(id string, alias string, indexes []string) Migration {
+	return Migration{
+		ID:          id,
+		Description: fmt.Sprintf("Add alias %s to %s", alias, strings.Join(indexes, ", ")),
+		Up: func(c *Client) error {
+			_, err := c.AddAlias(alias, indexes)
+			return err
+		},
+	}
+}
+
+// ReindexMigration copies documents from an index to another, waiting for
+// the copy to complete
+func ReindexMigration(id string, body ReindexBody) Migration {
+	return Migration{
+		ID:          id,
+		Description: fmt.Sprintf("Reindex %s into %s", strings.Join(body.Source.Index, ", "), body.Dest.Index),
+		Up: func(c *Client) error {
+			resp, err := c.Reindex(body, url.Values{"refresh": []string{"true"}})
+			if err != nil {
+				return err
+			}
+			if len(resp.Failures) > 0 {
+				return fmt.Errorf("Reindexing into %s failed for %d documents: %s", body.Dest.Index, len(resp.Failures), resp.Failures[0])
+			}
+			if resp.TimedOut {
+				return fmt.Errorf("Reindexing into %s timed out", body.Dest.Index)
+			}
+			return nil
+		},
+	}
+}
+
+// PutPipelineMigration creates or replaces an ingest pipeline
+func PutPipelineMigration(id string, pipelineID string, pipeline Pipeline) Migration {
+	return Migration{
+		ID:          id,
+		Description: "Put pipeline " + pipelineID,
+		Up: func(c *Client) error {
+			_, err := c.PutPipeline(pipelineID, pipeline)
+			return err
+		},
+	}
+}
+
+// Migrator applies migrations in order, each of them once, recording the
+// applied ones in a history index. A lock document in the history index
+// prevents two deployers from migrating at the same time.
+type Migrator struct {
+	Client       *Client
+	HistoryIndex string
+
+	// Owner identifies the deployer in the lock, the host name and process
+	// id by default
+	Owner string
+
+	// DryRun only reports the pending migrations, without applying them or
+	// locking the history index
+	DryRun bool
+
+	// Log receives a line per applied, or pending with DryRun, migration
+	// when it is not nil
+	Log io.Writer
+}
+
+// NewMigrator returns a Migrator recording migrations in historyIndex
+func NewMigrator(c *Client, historyIndex string) *Migrator {
+	return &Migrator{Client: c, HistoryIndex: historyIndex}
+}
+
+// Migrate applies the migrations not recorded in the history index yet, in
+// order, and returns them. It stops at the first migration which fails,
+// leaving it pending. With DryRun, the pending migrations are returned
+// without being applied. Failing to release the lock is only reported when
+// the migrations succeeded.
+func (m *Migrator) Migrate(migrations []Migration) (applied []Migration, err error) {
+	if err := validateMigrations(migrations); err != nil {
+		return nil, err
+	}
+
+	if m.DryRun {
+		pending, err := m.Pending(migrations)
+		for _, migration := range pending {
+			m.logf("%s: %s (dry run)", migration.ID, migration.Description)
+		}
+		return pending, err
+	}
+
+	if err := m.ensureHistoryIndex(); err != nil {
+		return nil, err
+	}
+	if err := m.lock(); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if unlockErr := m.Unlock(); err == nil {
+			err = unlockErr
+		}
+	}()
+
+	// Pending migrations are only known for sure once the lock is held
+	pending, err := m.Pending(migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, migration := range pending {
+		start := time.Now()
+		if err := migration.Up(m.Client); err != nil {
+			return pending[:i], fmt.Errorf("Migration %s failed: %s", migration.ID, err)
+		}
+
+		record := MigrationRecord{
+			ID:             migration.ID,
+			Description:    migration.Description,
+			AppliedAt:      start.UTC(),
+			DurationMillis: int64(time.Since(start) / time.Millisecond),
+		}
+		if err := m.put(migration.ID, record, nil); err != nil {
+			return pending[:i], err
+		}
+		m.logf("%s: %s (%dms)", migration.ID, migration.Description, record.DurationMillis)
+	}
+
+	return pending, nil
+}
+
+// Pending returns the migrations which are not recorded in the history index,
+// in order
+func (m *Migrator) Pending(migrations []Migration) ([]Migration, error) {
+	history, err := m.History()
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[string]bool, len(history))
+	for _, record := range history {
+		applied[record.ID] = true
+	}
+
+	var pending []Migration
+	for _, migration := range migrations {
+		if !applied[migration.ID] {
+			pending = append(pending, migration)
+		}
+	}
+
+	return pending, nil
+}
+
+// History returns the migrations recorded in the history index, by date of
+// application. It is empty if the history index does not exist.
+func (m *Migrator) History() ([]MigrationRecord, error) {
+	r := Request{
+		Query: map[string]interface{}{
+			"query": map[string]interface{}{
+				"exists": map[string]interface{}{"field": "applied_at"},
+			},
+			"sort": []interface{}{map[string]interface{}{
+				"applied_at": map[string]interface{}{"order": "asc", "unmapped_type": "date"},
+			}},
+			"size": 10000,
+		},
+		IndexList: []string{m.HistoryIndex},
+		Method:    "POST",
+		API:       "_search",
+	}
+
+	var resp struct {
+		Hits struct {
+			Hits []struct {
+				Source MigrationRecord `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	err := m.Client.doInto(&r, &resp)
+	if searchErr, ok := err.(*SearchError); ok && searchErr.StatusCode == 404 {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]MigrationRecord, len(resp.Hits.Hits))
+	for i, hit := range resp.Hits.Hits {
+		history[i] = hit.Source
+	}
+
+	return history, nil
+}
+
+// Unlock releases the lock of the history index. Migrate releases it once
+// done, Unlock is only needed to release the lock of a deployer which died
+// while migrating.
+func (m *Migrator) Unlock() error {
+	docType, err := m.docType()
+	if err != nil {
+		return err
+	}
+
+	_, err = m.Client.Delete(Document{
+		Index: m.HistoryIndex,
+		Type:  docType,
+		ID:    migrationLockID,
+	}, url.Values{"refresh": []string{"true"}})
+
+	return err
+}
+
+func (m *Migrator) lock() error {
+	owner := m.Owner
+	if owner == "" {
+		host, _ := os.Hostname()
+		owner = fmt.Sprintf("%s:%d", host, os.Getpid())
+	}
+
+	lock := migrationLock{Owner: owner, AcquiredAt: time.Now().UTC()}
+	err := m.put(migrationLockID, lock, url.Values{"op_type": []string{"create"}})
+	if IsConflict(err) {
+		return ErrMigrationLocked
+	}
+
+	return err
+}
+
+func (m *Migrator) put(id string, fields interface{}, extraArgs url.Values) error {
+	docType, err := m.docType()
+	if err != nil {
+		return err
+	}
+
+	args := copyArgs(extraArgs, 1)
+	args.Set("refresh", "true")
+
+	_, err = m.Client.Index(Document{
+		Index:  m.HistoryIndex,
+		Type:   docType,
+		ID:     id,
+		Fields: fields,
+	}, args)
+
+	return err
+}
+
+func (m *Migrator) ensureHistoryIndex() error {
+	exists, err := m.Client.IndicesExist([]string{m.HistoryIndex})
+	if err != nil || exists {
+		return err
+	}
+
+	_, err = m.Client.CreateIndex(m.HistoryIndex, map[string]interface{}{
+		"settings": map[string]interface{}{"number_of_shards": 1},
+	})
+	if err != nil {
+		// Another deployer may have created it in the meantime
+		if exists, _ := m.Client.IndicesExist([]string{m.HistoryIndex}); exists {
+			return nil
+		}
+	}
+
+	return err
+}
+
+// docType returns the type of the documents of the history index
+func (m *Migrator) docType() (string, error) {
+	typeless, err := m.Client.versionAtLeast("7")
+	if err != nil {
+		return "", err
+	}
+	if typeless {
+		return "_doc", nil
+	}
+	return "migration", nil
+}
+
+func (m *Migrator) logf(format string, args ...interface{}) {
+	if m.Log != nil {
+		fmt.Fprintf(m.Log, format+"\n", args...)
+	}
+}
+
+func validateMigrations(migrations []Migration) error {
+	ids := make(map[string]bool, len(migrations))
+	for _, migration := range migrations {
+		switch {
+		case migration.ID == "" || migration.ID == migrationLockID:
+			return fmt.Errorf("Invalid migration id %q", migration.ID)
+		case ids[migration.ID]:
+			return fmt.Errorf("Duplicate migration id %q", migration.ID)
+		case migration.Up == nil:
+			return fmt.Errorf("Migration %s has no Up function", migration.ID)
+		}
+		ids[migration.ID] = true
+	}
+	return nil
+}
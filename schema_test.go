@@ -0,0 +1,148 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+
+	. "github.com/go-check/check"
+)
+
+func (s *GoesTestSuite) TestMigrator(c *C) {
+	historyIndex := "testmigrator_history"
+	indexName := "testmigrator"
+	alias := "testmigrator_alias"
+	pipelineID := "testmigrator"
+
+	conn := NewClient(ESHost, ESPort)
+	if supported, _ := conn.versionAtLeast("5"); !supported {
+		c.Skip("Ingest pipelines require ES 5, skipping this test")
+	}
+	conn.DeleteIndex(historyIndex)
+	conn.DeleteIndex(indexName)
+	conn.DeletePipeline(pipelineID)
+	defer conn.DeleteIndex(historyIndex)
+	defer conn.DeleteIndex(indexName)
+	defer conn.DeletePipeline(pipelineID)
+
+	migrations := []Migration{
+		CreateIndexMigration("001_create", indexName, map[string]interface{}{}),
+		AddAliasMigration("002_alias", alias, []string{indexName}),
+		PutPipelineMigration("003_pipeline", pipelineID, Pipeline{
+			Processors: []map[string]interface{}{
+				{"set": map[string]interface{}{"field": "migrated", "value": true}},
+			},
+		}),
+	}
+
+	// A dry run applies and records nothing
+	var log bytes.Buffer
+	migrator := NewMigrator(conn, historyIndex)
+	migrator.DryRun = true
+	migrator.Log = &log
+	pending, err := migrator.Migrate(migrations)
+	c.Assert(err, IsNil)
+	c.Assert(pending, HasLen, 3)
+	c.Assert(log.String(), Equals, "001_create: Create index testmigrator (dry run)\n"+
+		"002_alias: Add alias testmigrator_alias to testmigrator (dry run)\n"+
+		"003_pipeline: Put pipeline testmigrator (dry run)\n")
+
+	exists, err := conn.IndicesExist([]string{historyIndex, indexName})
+	c.Assert(err, IsNil)
+	c.Assert(exists, Equals, false)
+
+	migrator = NewMigrator(conn, historyIndex)
+	applied, err := migrator.Migrate(migrations)
+	c.Assert(err, IsNil)
+	c.Assert(applied, HasLen, 3)
+
+	indexes, err := conn.ResolveAlias(alias)
+	c.Assert(err, IsNil)
+	c.Assert(indexes, DeepEquals, []string{indexName})
+
+	history, err := migrator.History()
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 3)
+	for i, record := range history {
+		c.Assert(record.ID, Equals, migrations[i].ID)
+		c.Assert(record.Description, Equals, migrations[i].Description)
+		c.Assert(record.AppliedAt.IsZero(), Equals, false)
+	}
+
+	// Migrations are applied once
+	applied, err = migrator.Migrate(migrations)
+	c.Assert(err, IsNil)
+	c.Assert(applied, HasLen, 0)
+
+	// A failed migration stays pending
+	ran := 0
+	migrations = append(migrations,
+		Migration{ID: "004_ok", Up: func(c *Client) error { ran++; return nil }},
+		Migration{ID: "005_fail", Up: func(c *Client) error { return errors.New("boom") }},
+	)
+	applied, err = migrator.Migrate(migrations)
+	c.Assert(err, ErrorMatches, "Migration 005_fail failed: boom")
+	c.Assert(applied, HasLen, 1)
+	c.Assert(applied[0].ID, Equals, "004_ok")
+	c.Assert(ran, Equals, 1)
+
+	pending, err = migrator.Pending(migrations)
+	c.Assert(err, IsNil)
+	c.Assert(pending, HasLen, 1)
+	c.Assert(pending[0].ID, Equals, "005_fail")
+
+	// Only one deployer migrates at a time
+	c.Assert(migrator.lock(), IsNil)
+	_, err = NewMigrator(conn, historyIndex).Migrate(migrations[:4])
+	c.Assert(err, Equals, ErrMigrationLocked)
+	c.Assert(migrator.Unlock(), IsNil)
+
+	history, err = migrator.History()
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 4)
+}
+
+func (s *GoesTestSuite) TestMigratorValidation(c *C) {
+	migrator := NewMigrator(NewClient(ESHost, ESPort), "testmigrator_history")
+	up := func(c *Client) error { return nil }
+
+	_, err := migrator.Migrate([]Migration{{ID: "", Up: up}})
+	c.Assert(err, ErrorMatches, `Invalid migration id ""`)
+
+	_, err = migrator.Migrate([]Migration{{ID: "lock", Up: up}})
+	c.Assert(err, ErrorMatches, `Invalid migration id "lock"`)
+
+	_, err = migrator.Migrate([]Migration{{ID: "001", Up: up}, {ID: "001", Up: up}})
+	c.Assert(err, ErrorMatches, `Duplicate migration id "001"`)
+
+	_, err = migrator.Migrate([]Migration{{ID: "001"}})
+	c.Assert(err, ErrorMatches, "Migration 001 has no Up function")
+}
+
+func (s *GoesTestSuite) TestReindexMigrationFailures(c *C) {
+	response := `{"total": 2, "created": 1, "failures": [{"id": "2", "status": 400}]}`
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(response))
+	})
+	defer ts.Close()
+
+	migration := ReindexMigration("001", ReindexBody{
+		Source: ReindexSource{Index: []string{"a"}},
+		Dest:   ReindexDest{Index: "b"},
+	})
+
+	err := migration.Up(conn)
+	c.Assert(err, ErrorMatches, `Reindexing into b failed for 1 documents: {"id": "2", "status": 400}`)
+
+	response = `{"total": 2, "created": 1, "timed_out": true, "failures": []}`
+	err = migration.Up(conn)
+	c.Assert(err, ErrorMatches, "Reindexing into b timed out")
+
+	response = `{"total": 2, "created": 2, "failures": []}`
+	c.Assert(migration.Up(conn), IsNil)
+}
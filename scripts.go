@@ -0,0 +1,117 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"encoding/json"
+	"net/url"
+)
+
+// StoredScript holds a script stored in the cluster. Search templates are
+// stored scripts in the mustache language.
+type StoredScript struct {
+	Lang    string            `json:"lang"`
+	Source  string            `json:"source"`
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// SearchTemplate holds a search template to run or render, either stored
+// with ID or given inline in Source, and the params it is rendered with
+type SearchTemplate struct {
+	ID      string                 `json:"id,omitempty"`
+	Source  interface{}            `json:"source,omitempty"`
+	Params  map[string]interface{} `json:"params,omitempty"`
+	Explain bool                   `json:"explain,omitempty"`
+	Profile bool                   `json:"profile,omitempty"`
+}
+
+// PutScript stores a script, which can then be referenced by id in queries,
+// updates and aggregations
+func (c *Client) PutScript(id string, script StoredScript) (*Response, error) {
+	r := Request{
+		Query:  map[string]interface{}{"script": script},
+		Method: "PUT",
+		API:    "_scripts/" + id,
+	}
+
+	return c.Do(&r)
+}
+
+// GetScript returns a stored script. An error with a 404 status code is
+// returned if it does not exist.
+func (c *Client) GetScript(id string) (*StoredScript, error) {
+	r := Request{
+		Method: "GET",
+		API:    "_scripts/" + id,
+	}
+
+	var resp struct {
+		Script *StoredScript `json:"script"`
+	}
+	if err := c.doInto(&r, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Script, nil
+}
+
+// DeleteScript deletes a stored script
+func (c *Client) DeleteScript(id string) (*Response, error) {
+	r := Request{
+		Method: "DELETE",
+		API:    "_scripts/" + id,
+	}
+
+	return c.Do(&r)
+}
+
+// PutSearchTemplate stores a mustache search template. source is either the
+// template as a string, or a query which is encoded to JSON.
+func (c *Client) PutSearchTemplate(id string, source interface{}) (*Response, error) {
+	template, ok := source.(string)
+	if !ok {
+		data, err := json.Marshal(source)
+		if err != nil {
+			return nil, err
+		}
+		template = string(data)
+	}
+
+	return c.PutScript(id, StoredScript{Lang: "mustache", Source: template})
+}
+
+// SearchTemplate renders a search template with its params and runs the
+// resulting search, whose response is decoded as by Search
+func (c *Client) SearchTemplate(template SearchTemplate, indexList []string, typeList []string, extraArgs url.Values) (*Response, error) {
+	r := Request{
+		Query:     template,
+		IndexList: indexList,
+		TypeList:  typeList,
+		Method:    "POST",
+		API:       "_search/template",
+		ExtraArgs: extraArgs,
+	}
+
+	return c.Do(&r)
+}
+
+// RenderSearchTemplate renders a search template with its params and returns
+// the resulting search body, without running it
+func (c *Client) RenderSearchTemplate(template SearchTemplate) (map[string]interface{}, error) {
+	r := Request{
+		Query:  template,
+		Method: "POST",
+		API:    "_render/template",
+	}
+
+	var resp struct {
+		TemplateOutput map[string]interface{} `json:"template_output"`
+	}
+	if err := c.doInto(&r, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.TemplateOutput, nil
+}
@@ -0,0 +1,135 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	. "github.com/go-check/check"
+)
+
+func (s *GoesTestSuite) TestStoredScript(c *C) {
+	scriptID := "testscript"
+
+	conn := NewClient(ESHost, ESPort)
+	if supported, _ := conn.versionAtLeast("6"); !supported {
+		c.Skip("The stored script API requires ES 6, skipping this test")
+	}
+	conn.DeleteScript(scriptID)
+
+	_, err := conn.PutScript(scriptID, StoredScript{
+		Lang:   "painless",
+		Source: "doc['count'].value * params.factor",
+	})
+	c.Assert(err, IsNil)
+	defer conn.DeleteScript(scriptID)
+
+	script, err := conn.GetScript(scriptID)
+	c.Assert(err, IsNil)
+	c.Assert(script.Lang, Equals, "painless")
+	c.Assert(script.Source, Equals, "doc['count'].value * params.factor")
+
+	_, err = conn.DeleteScript(scriptID)
+	c.Assert(err, IsNil)
+
+	_, err = conn.GetScript(scriptID)
+	c.Assert(err, ErrorMatches, `\[404\] .*`)
+}
+
+func (s *GoesTestSuite) TestSearchTemplate(c *C) {
+	indexName := "testsearchtemplate"
+	docType := "tweet"
+	templateID := "testsearchtemplate"
+
+	conn := NewClient(ESHost, ESPort)
+	if supported, _ := conn.versionAtLeast("6"); !supported {
+		c.Skip("The stored script API requires ES 6, skipping this test")
+	}
+	conn.DeleteIndex(indexName)
+	conn.DeleteScript(templateID)
+
+	_, err := conn.CreateIndex(indexName, map[string]interface{}{})
+	c.Assert(err, IsNil)
+	defer conn.DeleteIndex(indexName)
+
+	_, err = conn.BulkSend([]Document{
+		{Index: indexName, Type: docType, ID: "1", BulkCommand: BulkCommandIndex, Fields: map[string]interface{}{"user": "foo"}},
+		{Index: indexName, Type: docType, ID: "2", BulkCommand: BulkCommandIndex, Fields: map[string]interface{}{"user": "bar"}},
+	})
+	c.Assert(err, IsNil)
+	_, err = conn.RefreshIndex(indexName)
+	c.Assert(err, IsNil)
+
+	_, err = conn.PutSearchTemplate(templateID, map[string]interface{}{
+		"query": map[string]interface{}{
+			"match": map[string]interface{}{"user": "{{user}}"},
+		},
+	})
+	c.Assert(err, IsNil)
+	defer conn.DeleteScript(templateID)
+
+	script, err := conn.GetScript(templateID)
+	c.Assert(err, IsNil)
+	c.Assert(script.Lang, Equals, "mustache")
+
+	template := SearchTemplate{ID: templateID, Params: map[string]interface{}{"user": "foo"}}
+	rendered, err := conn.RenderSearchTemplate(template)
+	c.Assert(err, IsNil)
+	c.Assert(rendered, DeepEquals, map[string]interface{}{
+		"query": map[string]interface{}{
+			"match": map[string]interface{}{"user": "foo"},
+		},
+	})
+
+	resp, err := conn.SearchTemplate(template, []string{indexName}, nil, url.Values{})
+	c.Assert(err, IsNil)
+	c.Assert(resp.Hits.Total.Value, Equals, uint64(1))
+	c.Assert(resp.Hits.Hits[0].ID, Equals, "1")
+
+	// Inline template
+	resp, err = conn.SearchTemplate(SearchTemplate{
+		Source: `{"query": {"match": {"user": "{{user}}"}}, "size": {{size}}}`,
+		Params: map[string]interface{}{"user": "bar", "size": 1},
+	}, []string{indexName}, nil, url.Values{})
+	c.Assert(err, IsNil)
+	c.Assert(resp.Hits.Hits, HasLen, 1)
+	c.Assert(resp.Hits.Hits[0].ID, Equals, "2")
+}
+
+func (s *GoesTestSuite) TestSearchTemplateRequest(c *C) {
+	var requests []string
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		data, err := ioutil.ReadAll(r.Body)
+		c.Check(err, IsNil)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(data))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"hits": {"total": 0, "hits": []}}`))
+	})
+	defer ts.Close()
+
+	_, err := conn.PutSearchTemplate("tpl", `{"query": {{#toJson}}query{{/toJson}}}`)
+	c.Assert(err, IsNil)
+
+	_, err = conn.PutSearchTemplate("obj", map[string]interface{}{"size": "{{size}}"})
+	c.Assert(err, IsNil)
+
+	_, err = conn.SearchTemplate(SearchTemplate{
+		ID:     "tpl",
+		Params: map[string]interface{}{"query": map[string]interface{}{"match_all": map[string]interface{}{}}},
+	}, []string{"a", "b"}, nil, url.Values{})
+	c.Assert(err, IsNil)
+
+	c.Assert(requests, HasLen, 3)
+	c.Assert(requests[0], Equals, `PUT /_scripts/tpl {"script":{"lang":"mustache","source":"{\"query\": {{#toJson}}query{{/toJson}}}"}}`)
+
+	var body map[string]map[string]interface{}
+	c.Assert(json.Unmarshal([]byte(requests[1][len("PUT /_scripts/obj "):]), &body), IsNil)
+	c.Assert(body["script"]["source"], Equals, `{"size":"{{size}}"}`)
+
+	c.Assert(requests[2], Equals, `POST /a,b/_search/template {"id":"tpl","params":{"query":{"match_all":{}}}}`)
+}
@@ -0,0 +1,298 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// SnapshotStateInProgress is the state of a snapshot being created
+	SnapshotStateInProgress = "IN_PROGRESS"
+	// SnapshotStateSuccess is the state of a complete snapshot
+	SnapshotStateSuccess = "SUCCESS"
+	// SnapshotStatePartial is the state of a snapshot missing some shards
+	SnapshotStatePartial = "PARTIAL"
+	// SnapshotStateFailed is the state of a snapshot which could not be created
+	SnapshotStateFailed = "FAILED"
+)
+
+// SnapshotRepository holds the type and settings of a snapshot repository
+type SnapshotRepository struct {
+	Type     string                 `json:"type"`
+	Settings map[string]interface{} `json:"settings"`
+}
+
+// FSRepository returns a repository storing snapshots in a shared filesystem.
+// location must be listed in the path.repo setting of every node.
+func FSRepository(location string) SnapshotRepository {
+	return SnapshotRepository{
+		Type:     "fs",
+		Settings: map[string]interface{}{"location": location},
+	}
+}
+
+// URLRepository returns a read-only repository fetching snapshots from a URL,
+// which must be listed in the repositories.url.allowed_urls setting
+func URLRepository(url string) SnapshotRepository {
+	return SnapshotRepository{
+		Type:     "url",
+		Settings: map[string]interface{}{"url": url},
+	}
+}
+
+// SnapshotBody describes what a snapshot contains
+type SnapshotBody struct {
+	Indices            []string               `json:"indices,omitempty"`
+	IgnoreUnavailable  bool                   `json:"ignore_unavailable,omitempty"`
+	IncludeGlobalState *bool                  `json:"include_global_state,omitempty"`
+	Partial            bool                   `json:"partial,omitempty"`
+	Metadata           map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// SnapshotInfo describes a snapshot
+type SnapshotInfo struct {
+	Snapshot          string            `json:"snapshot"`
+	UUID              string            `json:"uuid"`
+	Indices           []string          `json:"indices"`
+	State             string            `json:"state"`
+	StartTimeInMillis int64             `json:"start_time_in_millis"`
+	EndTimeInMillis   int64             `json:"end_time_in_millis"`
+	DurationInMillis  int64             `json:"duration_in_millis"`
+	Failures          []json.RawMessage `json:"failures"`
+	Shards            Shard             `json:"shards"`
+}
+
+// SnapshotStatus holds the detailed progress of a snapshot
+type SnapshotStatus struct {
+	Snapshot    string `json:"snapshot"`
+	Repository  string `json:"repository"`
+	UUID        string `json:"uuid"`
+	State       string `json:"state"`
+	ShardsStats struct {
+		Initializing uint64 `json:"initializing"`
+		Started      uint64 `json:"started"`
+		Finalizing   uint64 `json:"finalizing"`
+		Done         uint64 `json:"done"`
+		Failed       uint64 `json:"failed"`
+		Total        uint64 `json:"total"`
+	} `json:"shards_stats"`
+	Stats map[string]interface{} `json:"stats"`
+}
+
+// RestoreBody describes what is restored from a snapshot and how
+type RestoreBody struct {
+	Indices             []string               `json:"indices,omitempty"`
+	IgnoreUnavailable   bool                   `json:"ignore_unavailable,omitempty"`
+	IncludeGlobalState  bool                   `json:"include_global_state,omitempty"`
+	IncludeAliases      *bool                  `json:"include_aliases,omitempty"`
+	Partial             bool                   `json:"partial,omitempty"`
+	RenamePattern       string                 `json:"rename_pattern,omitempty"`
+	RenameReplacement   string                 `json:"rename_replacement,omitempty"`
+	IndexSettings       map[string]interface{} `json:"index_settings,omitempty"`
+	IgnoreIndexSettings []string               `json:"ignore_index_settings,omitempty"`
+}
+
+// SnapshotResponse holds the response of CreateSnapshot and RestoreSnapshot.
+// Snapshot is only set when wait_for_completion=true.
+type SnapshotResponse struct {
+	Accepted bool          `json:"accepted"`
+	Snapshot *SnapshotInfo `json:"snapshot"`
+}
+
+// PutRepository registers a snapshot repository
+func (c *Client) PutRepository(name string, repository SnapshotRepository) (*Response, error) {
+	r := Request{
+		Query:  repository,
+		Method: "PUT",
+		API:    "_snapshot/" + name,
+	}
+
+	return c.Do(&r)
+}
+
+// GetRepository returns the snapshot repositories by name, all of them if no
+// name is given
+func (c *Client) GetRepository(names []string) (map[string]SnapshotRepository, error) {
+	r := Request{
+		Method: "GET",
+		API:    namedAPI("_snapshot", names),
+	}
+
+	repositories := map[string]SnapshotRepository{}
+	return repositories, c.doInto(&r, &repositories)
+}
+
+// DeleteRepository unregisters a snapshot repository, its snapshots are kept
+func (c *Client) DeleteRepository(name string) (*Response, error) {
+	r := Request{
+		Method: "DELETE",
+		API:    "_snapshot/" + name,
+	}
+
+	return c.Do(&r)
+}
+
+// CreateSnapshot starts a snapshot in a repository. Use WaitForSnapshot, or
+// wait_for_completion=true in extraArgs, to wait for it to complete.
+func (c *Client) CreateSnapshot(repository string, snapshot string, body SnapshotBody, extraArgs url.Values) (*SnapshotResponse, error) {
+	r := Request{
+		Query:     body,
+		Method:    "PUT",
+		API:       "_snapshot/" + repository + "/" + snapshot,
+		ExtraArgs: extraArgs,
+	}
+
+	resp := &SnapshotResponse{}
+	return resp, c.doInto(&r, resp)
+}
+
+// GetSnapshots returns snapshots of a repository by name, all of them if no
+// name is given
+func (c *Client) GetSnapshots(repository string, snapshots []string) ([]SnapshotInfo, error) {
+	if len(snapshots) == 0 {
+		snapshots = []string{"_all"}
+	}
+
+	r := Request{
+		Method: "GET",
+		API:    "_snapshot/" + repository + "/" + strings.Join(snapshots, ","),
+	}
+
+	var resp struct {
+		Snapshots []SnapshotInfo `json:"snapshots"`
+	}
+	if err := c.doInto(&r, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Snapshots, nil
+}
+
+// SnapshotStatus returns the detailed progress of a snapshot
+func (c *Client) SnapshotStatus(repository string, snapshot string) (*SnapshotStatus, error) {
+	r := Request{
+		Method: "GET",
+		API:    "_snapshot/" + repository + "/" + snapshot + "/_status",
+	}
+
+	var resp struct {
+		Snapshots []SnapshotStatus `json:"snapshots"`
+	}
+	if err := c.doInto(&r, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Snapshots) == 0 {
+		return nil, fmt.Errorf("No status returned for snapshot %s", snapshot)
+	}
+
+	return &resp.Snapshots[0], nil
+}
+
+// DeleteSnapshot deletes a snapshot, aborting it if it is in progress
+func (c *Client) DeleteSnapshot(repository string, snapshot string) (*Response, error) {
+	r := Request{
+		Method: "DELETE",
+		API:    "_snapshot/" + repository + "/" + snapshot,
+	}
+
+	return c.Do(&r)
+}
+
+// RestoreSnapshot starts restoring indexes from a snapshot. Restored indexes
+// must be closed or renamed with RenamePattern and RenameReplacement if they
+// exist. Use WaitForRestore, or wait_for_completion=true in extraArgs, to wait
+// for it to complete.
+func (c *Client) RestoreSnapshot(repository string, snapshot string, body RestoreBody, extraArgs url.Values) (*SnapshotResponse, error) {
+	r := Request{
+		Query:     body,
+		Method:    "POST",
+		API:       "_snapshot/" + repository + "/" + snapshot + "/_restore",
+		ExtraArgs: extraArgs,
+	}
+
+	resp := &SnapshotResponse{}
+	return resp, c.doInto(&r, resp)
+}
+
+// WaitForSnapshot polls a snapshot every interval until it is not in progress
+// anymore, or returns ErrWaitTimeout once timeout passed. An error is returned
+// if the snapshot failed, a partial snapshot is returned as is.
+func (c *Client) WaitForSnapshot(repository string, snapshot string, interval time.Duration, timeout time.Duration) (*SnapshotInfo, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		snapshots, err := c.GetSnapshots(repository, []string{snapshot})
+		if err != nil {
+			return nil, err
+		}
+		if len(snapshots) == 0 {
+			return nil, fmt.Errorf("Snapshot %s not found", snapshot)
+		}
+
+		info := &snapshots[0]
+		switch info.State {
+		case SnapshotStateInProgress:
+			if !time.Now().Before(deadline) {
+				return info, ErrWaitTimeout
+			}
+			time.Sleep(interval)
+		case SnapshotStateFailed:
+			return info, fmt.Errorf("Snapshot %s failed", snapshot)
+		default:
+			return info, nil
+		}
+	}
+}
+
+// WaitForRestore polls the recovery of the restored indexes every interval
+// until all their shards are recovered, or returns ErrWaitTimeout once
+// timeout passed. An error is returned if none of the indexes is recovering.
+func (c *Client) WaitForRestore(indices []string, interval time.Duration, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	r := Request{
+		IndexList: indices,
+		Method:    "GET",
+		API:       "_recovery",
+	}
+
+	for {
+		var recovery map[string]struct {
+			Shards []struct {
+				Stage string `json:"stage"`
+			} `json:"shards"`
+		}
+		if err := c.doInto(&r, &recovery); err != nil {
+			return err
+		}
+
+		if len(recovery) == 0 {
+			return fmt.Errorf("No recovery found for %s", strings.Join(indices, ","))
+		}
+
+		done := true
+		for _, index := range recovery {
+			if len(index.Shards) == 0 {
+				done = false
+			}
+			for _, shard := range index.Shards {
+				if shard.Stage != "DONE" {
+					done = false
+				}
+			}
+		}
+		if done {
+			return nil
+		}
+
+		if !time.Now().Before(deadline) {
+			return ErrWaitTimeout
+		}
+		time.Sleep(interval)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	. "github.com/go-check/check"
+)
+
+func (s *GoesTestSuite) TestSnapshotRestore(c *C) {
+	// The repository must be listed in the path.repo setting of the server
+	location := os.Getenv("TEST_ELASTICSEARCH_REPO_PATH")
+	if location == "" {
+		c.Skip("TEST_ELASTICSEARCH_REPO_PATH is not set, skipping this test")
+	}
+
+	repository := "testsnapshotrepository"
+	snapshot := "testsnapshot"
+	indexName := "testsnapshotrestore"
+	restoredName := "restored_testsnapshotrestore"
+	docType := "tweet"
+
+	conn := NewClient(ESHost, ESPort)
+	conn.DeleteIndex(indexName)
+	conn.DeleteIndex(restoredName)
+	conn.DeleteSnapshot(repository, snapshot)
+
+	_, err := conn.PutRepository(repository, FSRepository(location))
+	c.Assert(err, IsNil)
+	defer conn.DeleteRepository(repository)
+
+	repositories, err := conn.GetRepository([]string{repository})
+	c.Assert(err, IsNil)
+	c.Assert(repositories[repository].Type, Equals, "fs")
+
+	_, err = conn.CreateIndex(indexName, map[string]interface{}{})
+	c.Assert(err, IsNil)
+	defer conn.DeleteIndex(indexName)
+	defer conn.DeleteIndex(restoredName)
+
+	d := Document{
+		Index:  indexName,
+		Type:   docType,
+		ID:     "1",
+		Fields: map[string]interface{}{"user": "foo"},
+	}
+	_, err = conn.Index(d, url.Values{"refresh": []string{"true"}})
+	c.Assert(err, IsNil)
+
+	response, err := conn.CreateSnapshot(repository, snapshot, SnapshotBody{
+		Indices: []string{indexName},
+		Partial: true,
+	}, url.Values{})
+	c.Assert(err, IsNil)
+	defer conn.DeleteSnapshot(repository, snapshot)
+	c.Assert(response.Snapshot, IsNil)
+
+	info, err := conn.WaitForSnapshot(repository, snapshot, 100*time.Millisecond, time.Minute)
+	c.Assert(err, IsNil)
+	c.Assert(info.State, Equals, SnapshotStateSuccess)
+	c.Assert(info.Indices, DeepEquals, []string{indexName})
+
+	status, err := conn.SnapshotStatus(repository, snapshot)
+	c.Assert(err, IsNil)
+	c.Assert(status.State, Equals, SnapshotStateSuccess)
+	c.Assert(status.ShardsStats.Done, Equals, status.ShardsStats.Total)
+
+	snapshots, err := conn.GetSnapshots(repository, nil)
+	c.Assert(err, IsNil)
+	c.Assert(snapshots, HasLen, 1)
+
+	_, err = conn.RestoreSnapshot(repository, snapshot, RestoreBody{
+		Indices:           []string{indexName},
+		RenamePattern:     "(.+)",
+		RenameReplacement: "restored_$1",
+		IndexSettings: map[string]interface{}{
+			"index.number_of_replicas": 0,
+		},
+	}, url.Values{})
+	c.Assert(err, IsNil)
+
+	err = conn.WaitForRestore([]string{restoredName}, 100*time.Millisecond, time.Minute)
+	c.Assert(err, IsNil)
+
+	doc, err := conn.Get(restoredName, docType, "1", url.Values{})
+	c.Assert(err, IsNil)
+	c.Assert(doc.Source["user"], Equals, "foo")
+
+	_, err = conn.DeleteSnapshot(repository, snapshot)
+	c.Assert(err, IsNil)
+
+	snapshots, err = conn.GetSnapshots(repository, nil)
+	c.Assert(err, IsNil)
+	c.Assert(snapshots, HasLen, 0)
+}
+
+func (s *GoesTestSuite) TestWaitForSnapshotTimeout(c *C) {
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"snapshots": [{"snapshot": "snap", "state": "IN_PROGRESS"}]}`))
+	})
+	defer ts.Close()
+
+	info, err := conn.WaitForSnapshot("repo", "snap", time.Millisecond, 10*time.Millisecond)
+	c.Assert(err, Equals, ErrWaitTimeout)
+	c.Assert(info.State, Equals, SnapshotStateInProgress)
+}
+
+func (s *GoesTestSuite) TestWaitForRestore(c *C) {
+	polls := 0
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/empty/_recovery":
+			w.Write([]byte(`{}`))
+		case "/pending/_recovery":
+			w.Write([]byte(`{"pending": {"shards": [{"stage": "INDEX"}]}}`))
+		default:
+			polls++
+			if polls < 3 {
+				w.Write([]byte(`{"restored": {"shards": [{"stage": "DONE"}, {"stage": "INDEX"}]}}`))
+				return
+			}
+			w.Write([]byte(`{"restored": {"shards": [{"stage": "DONE"}, {"stage": "DONE"}]}}`))
+		}
+	})
+	defer ts.Close()
+
+	c.Assert(conn.WaitForRestore([]string{"restored"}, time.Millisecond, time.Second), IsNil)
+	c.Assert(polls, Equals, 3)
+
+	err := conn.WaitForRestore([]string{"empty"}, time.Millisecond, time.Second)
+	c.Assert(err, ErrorMatches, "No recovery found for empty")
+
+	err = conn.WaitForRestore([]string{"pending"}, time.Millisecond, 10*time.Millisecond)
+	c.Assert(err, Equals, ErrWaitTimeout)
+}
@@ -0,0 +1,323 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DocsStats holds the document counts of an index
+type DocsStats struct {
+	Count   int64 `json:"count"`
+	Deleted int64 `json:"deleted"`
+}
+
+// StoreStats holds the size of an index on disk
+type StoreStats struct {
+	SizeInBytes          int64 `json:"size_in_bytes"`
+	ThrottleTimeInMillis int64 `json:"throttle_time_in_millis"`
+}
+
+// IndexingStats holds indexing and deletion statistics
+type IndexingStats struct {
+	IndexTotal           int64 `json:"index_total"`
+	IndexTimeInMillis    int64 `json:"index_time_in_millis"`
+	IndexCurrent         int64 `json:"index_current"`
+	IndexFailed          int64 `json:"index_failed"`
+	DeleteTotal          int64 `json:"delete_total"`
+	DeleteTimeInMillis   int64 `json:"delete_time_in_millis"`
+	DeleteCurrent        int64 `json:"delete_current"`
+	NoopUpdateTotal      int64 `json:"noop_update_total"`
+	IsThrottled          bool  `json:"is_throttled"`
+	ThrottleTimeInMillis int64 `json:"throttle_time_in_millis"`
+}
+
+// GetStats holds statistics of the get API
+type GetStats struct {
+	Total               int64 `json:"total"`
+	TimeInMillis        int64 `json:"time_in_millis"`
+	ExistsTotal         int64 `json:"exists_total"`
+	ExistsTimeInMillis  int64 `json:"exists_time_in_millis"`
+	MissingTotal        int64 `json:"missing_total"`
+	MissingTimeInMillis int64 `json:"missing_time_in_millis"`
+	Current             int64 `json:"current"`
+}
+
+// SearchStats holds search statistics
+type SearchStats struct {
+	OpenContexts        int64 `json:"open_contexts"`
+	QueryTotal          int64 `json:"query_total"`
+	QueryTimeInMillis   int64 `json:"query_time_in_millis"`
+	QueryCurrent        int64 `json:"query_current"`
+	FetchTotal          int64 `json:"fetch_total"`
+	FetchTimeInMillis   int64 `json:"fetch_time_in_millis"`
+	FetchCurrent        int64 `json:"fetch_current"`
+	ScrollTotal         int64 `json:"scroll_total"`
+	ScrollTimeInMillis  int64 `json:"scroll_time_in_millis"`
+	ScrollCurrent       int64 `json:"scroll_current"`
+	SuggestTotal        int64 `json:"suggest_total"`
+	SuggestTimeInMillis int64 `json:"suggest_time_in_millis"`
+	SuggestCurrent      int64 `json:"suggest_current"`
+}
+
+// MergesStats holds segment merging statistics
+type MergesStats struct {
+	Current            int64 `json:"current"`
+	CurrentDocs        int64 `json:"current_docs"`
+	CurrentSizeInBytes int64 `json:"current_size_in_bytes"`
+	Total              int64 `json:"total"`
+	TotalTimeInMillis  int64 `json:"total_time_in_millis"`
+	TotalDocs          int64 `json:"total_docs"`
+	TotalSizeInBytes   int64 `json:"total_size_in_bytes"`
+}
+
+// RefreshStats holds refresh statistics
+type RefreshStats struct {
+	Total             int64 `json:"total"`
+	TotalTimeInMillis int64 `json:"total_time_in_millis"`
+}
+
+// FlushStats holds flush statistics
+type FlushStats struct {
+	Total             int64 `json:"total"`
+	TotalTimeInMillis int64 `json:"total_time_in_millis"`
+}
+
+// QueryCacheStats holds query cache statistics
+type QueryCacheStats struct {
+	MemorySizeInBytes int64 `json:"memory_size_in_bytes"`
+	TotalCount        int64 `json:"total_count"`
+	HitCount          int64 `json:"hit_count"`
+	MissCount         int64 `json:"miss_count"`
+	CacheSize         int64 `json:"cache_size"`
+	CacheCount        int64 `json:"cache_count"`
+	Evictions         int64 `json:"evictions"`
+}
+
+// RequestCacheStats holds shard request cache statistics
+type RequestCacheStats struct {
+	MemorySizeInBytes int64 `json:"memory_size_in_bytes"`
+	Evictions         int64 `json:"evictions"`
+	HitCount          int64 `json:"hit_count"`
+	MissCount         int64 `json:"miss_count"`
+}
+
+// FielddataStats holds fielddata memory usage
+type FielddataStats struct {
+	MemorySizeInBytes int64 `json:"memory_size_in_bytes"`
+	Evictions         int64 `json:"evictions"`
+}
+
+// SegmentsStats holds the number of segments and their memory usage
+type SegmentsStats struct {
+	Count                     int64 `json:"count"`
+	MemoryInBytes             int64 `json:"memory_in_bytes"`
+	TermsMemoryInBytes        int64 `json:"terms_memory_in_bytes"`
+	StoredFieldsMemoryInBytes int64 `json:"stored_fields_memory_in_bytes"`
+	TermVectorsMemoryInBytes  int64 `json:"term_vectors_memory_in_bytes"`
+	NormsMemoryInBytes        int64 `json:"norms_memory_in_bytes"`
+	DocValuesMemoryInBytes    int64 `json:"doc_values_memory_in_bytes"`
+	IndexWriterMemoryInBytes  int64 `json:"index_writer_memory_in_bytes"`
+	VersionMapMemoryInBytes   int64 `json:"version_map_memory_in_bytes"`
+	FixedBitSetMemoryInBytes  int64 `json:"fixed_bit_set_memory_in_bytes"`
+}
+
+// TranslogStats holds the size of the transaction log
+type TranslogStats struct {
+	Operations  int64 `json:"operations"`
+	SizeInBytes int64 `json:"size_in_bytes"`
+}
+
+// CommonStats holds the statistics of a set of shards, as returned for
+// indexes by the _stats API and for nodes by the _nodes/stats API
+type CommonStats struct {
+	Docs         DocsStats         `json:"docs"`
+	Store        StoreStats        `json:"store"`
+	Indexing     IndexingStats     `json:"indexing"`
+	Get          GetStats          `json:"get"`
+	Search       SearchStats       `json:"search"`
+	Merges       MergesStats       `json:"merges"`
+	Refresh      RefreshStats      `json:"refresh"`
+	Flush        FlushStats        `json:"flush"`
+	QueryCache   QueryCacheStats   `json:"query_cache"`
+	RequestCache RequestCacheStats `json:"request_cache"`
+	Fielddata    FielddataStats    `json:"fielddata"`
+	Segments     SegmentsStats     `json:"segments"`
+	Translog     TranslogStats     `json:"translog"`
+}
+
+// IndexStats holds the statistics of an index, for its primary shards and for
+// all its shards
+type IndexStats struct {
+	Primaries CommonStats `json:"primaries"`
+	Total     CommonStats `json:"total"`
+}
+
+// IndicesStatsResponse holds the response of the _stats API
+type IndicesStatsResponse struct {
+	Shards  Shard                 `json:"_shards"`
+	All     IndexStats            `json:"_all"`
+	Indices map[string]IndexStats `json:"indices"`
+}
+
+// JVMMemoryPoolStats holds the memory usage of a JVM memory pool
+type JVMMemoryPoolStats struct {
+	UsedInBytes     int64 `json:"used_in_bytes"`
+	MaxInBytes      int64 `json:"max_in_bytes"`
+	PeakUsedInBytes int64 `json:"peak_used_in_bytes"`
+	PeakMaxInBytes  int64 `json:"peak_max_in_bytes"`
+}
+
+// JVMGarbageCollectorStats holds the activity of a JVM garbage collector
+type JVMGarbageCollectorStats struct {
+	CollectionCount        int64 `json:"collection_count"`
+	CollectionTimeInMillis int64 `json:"collection_time_in_millis"`
+}
+
+// JVMStats holds the JVM statistics of a node
+type JVMStats struct {
+	Timestamp      int64 `json:"timestamp"`
+	UptimeInMillis int64 `json:"uptime_in_millis"`
+	Mem            struct {
+		HeapUsedInBytes         int64                         `json:"heap_used_in_bytes"`
+		HeapUsedPercent         int64                         `json:"heap_used_percent"`
+		HeapCommittedInBytes    int64                         `json:"heap_committed_in_bytes"`
+		HeapMaxInBytes          int64                         `json:"heap_max_in_bytes"`
+		NonHeapUsedInBytes      int64                         `json:"non_heap_used_in_bytes"`
+		NonHeapCommittedInBytes int64                         `json:"non_heap_committed_in_bytes"`
+		Pools                   map[string]JVMMemoryPoolStats `json:"pools"`
+	} `json:"mem"`
+	Threads struct {
+		Count     int64 `json:"count"`
+		PeakCount int64 `json:"peak_count"`
+	} `json:"threads"`
+	GC struct {
+		Collectors map[string]JVMGarbageCollectorStats `json:"collectors"`
+	} `json:"gc"`
+}
+
+// MemoryStats holds the usage of physical or swap memory
+type MemoryStats struct {
+	TotalInBytes int64 `json:"total_in_bytes"`
+	FreeInBytes  int64 `json:"free_in_bytes"`
+	UsedInBytes  int64 `json:"used_in_bytes"`
+	FreePercent  int64 `json:"free_percent"`
+	UsedPercent  int64 `json:"used_percent"`
+}
+
+// OSStats holds the operating system statistics of a node
+type OSStats struct {
+	Timestamp int64 `json:"timestamp"`
+	CPU       struct {
+		Percent     int64              `json:"percent"`
+		LoadAverage map[string]float64 `json:"load_average"`
+	} `json:"cpu"`
+	Mem  MemoryStats `json:"mem"`
+	Swap MemoryStats `json:"swap"`
+}
+
+// ThreadPoolStats holds the statistics of a thread pool. Rejected counts the
+// tasks rejected because the queue of the pool was full.
+type ThreadPoolStats struct {
+	Threads   int64 `json:"threads"`
+	Queue     int64 `json:"queue"`
+	Active    int64 `json:"active"`
+	Rejected  int64 `json:"rejected"`
+	Largest   int64 `json:"largest"`
+	Completed int64 `json:"completed"`
+}
+
+// BreakerStats holds the statistics of a circuit breaker
+type BreakerStats struct {
+	LimitSizeInBytes     int64   `json:"limit_size_in_bytes"`
+	LimitSize            string  `json:"limit_size"`
+	EstimatedSizeInBytes int64   `json:"estimated_size_in_bytes"`
+	EstimatedSize        string  `json:"estimated_size"`
+	Overhead             float64 `json:"overhead"`
+	Tripped              int64   `json:"tripped"`
+}
+
+// FSDataStats holds the disk usage of a data path
+type FSDataStats struct {
+	Path             string `json:"path"`
+	Mount            string `json:"mount"`
+	Type             string `json:"type"`
+	TotalInBytes     int64  `json:"total_in_bytes"`
+	FreeInBytes      int64  `json:"free_in_bytes"`
+	AvailableInBytes int64  `json:"available_in_bytes"`
+}
+
+// FSStats holds the disk usage of a node
+type FSStats struct {
+	Timestamp int64 `json:"timestamp"`
+	Total     struct {
+		TotalInBytes     int64 `json:"total_in_bytes"`
+		FreeInBytes      int64 `json:"free_in_bytes"`
+		AvailableInBytes int64 `json:"available_in_bytes"`
+	} `json:"total"`
+	Data []FSDataStats `json:"data"`
+}
+
+// NodeStats holds the statistics of a node
+type NodeStats struct {
+	Timestamp        int64                      `json:"timestamp"`
+	Name             string                     `json:"name"`
+	TransportAddress string                     `json:"transport_address"`
+	Host             string                     `json:"host"`
+	Roles            []string                   `json:"roles"`
+	Indices          CommonStats                `json:"indices"`
+	OS               OSStats                    `json:"os"`
+	JVM              JVMStats                   `json:"jvm"`
+	ThreadPool       map[string]ThreadPoolStats `json:"thread_pool"`
+	FS               FSStats                    `json:"fs"`
+	Breakers         map[string]BreakerStats    `json:"breakers"`
+}
+
+// NodesStatsResponse holds the response of the _nodes/stats API
+type NodesStatsResponse struct {
+	ClusterName string               `json:"cluster_name"`
+	Nodes       map[string]NodeStats `json:"nodes"`
+}
+
+// IndicesStats fetches the typed statistics of the indexes in indexList, all
+// of them if it is empty. metrics (e.g. docs, indexing, search) restricts the
+// returned statistics, all of them are returned if it is empty.
+func (c *Client) IndicesStats(indexList []string, metrics []string, extraArgs url.Values) (*IndicesStatsResponse, error) {
+	r := Request{
+		IndexList: indexList,
+		Method:    "GET",
+		API:       "_stats",
+		ExtraArgs: extraArgs,
+	}
+	if len(metrics) > 0 {
+		r.API += "/" + strings.Join(metrics, ",")
+	}
+
+	stats := &IndicesStatsResponse{}
+	return stats, c.doInto(&r, stats)
+}
+
+// NodesStats fetches the statistics of the nodes in nodeList, which may hold
+// node ids, names or _local, all of them if it is empty. metrics (e.g. jvm,
+// os, thread_pool, breaker, fs) restricts the returned statistics, all of
+// them are returned if it is empty.
+func (c *Client) NodesStats(nodeList []string, metrics []string, extraArgs url.Values) (*NodesStatsResponse, error) {
+	r := Request{
+		Method:    "GET",
+		API:       "_nodes",
+		ExtraArgs: extraArgs,
+	}
+	if len(nodeList) > 0 {
+		r.API += "/" + strings.Join(nodeList, ",")
+	}
+	r.API += "/stats"
+	if len(metrics) > 0 {
+		r.API += "/" + strings.Join(metrics, ",")
+	}
+
+	stats := &NodesStatsResponse{}
+	return stats, c.doInto(&r, stats)
+}
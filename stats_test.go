@@ -0,0 +1,178 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"net/http"
+	"net/url"
+
+	. "github.com/go-check/check"
+)
+
+func (s *GoesTestSuite) TestIndicesStats(c *C) {
+	indexName := "testindicesstats"
+	docType := "tweet"
+
+	conn := NewClient(ESHost, ESPort)
+	conn.DeleteIndex(indexName)
+
+	_, err := conn.CreateIndex(indexName, map[string]interface{}{
+		"settings": map[string]interface{}{
+			"index.number_of_shards":   1,
+			"index.number_of_replicas": 0,
+		},
+	})
+	c.Assert(err, IsNil)
+	defer conn.DeleteIndex(indexName)
+
+	_, err = conn.Index(Document{
+		Index:  indexName,
+		Type:   docType,
+		ID:     "1",
+		Fields: map[string]interface{}{"user": "foo"},
+	}, url.Values{"refresh": []string{"true"}})
+	c.Assert(err, IsNil)
+
+	_, err = conn.Search(map[string]interface{}{}, []string{indexName}, nil, url.Values{})
+	c.Assert(err, IsNil)
+
+	stats, err := conn.IndicesStats([]string{indexName}, nil, url.Values{})
+	c.Assert(err, IsNil)
+	c.Assert(stats.Indices, HasLen, 1)
+
+	index := stats.Indices[indexName]
+	c.Assert(index.Primaries.Docs.Count, Equals, int64(1))
+	c.Assert(index.Primaries.Indexing.IndexTotal, Equals, int64(1))
+	c.Assert(index.Total.Search.QueryTotal >= 1, Equals, true)
+	c.Assert(index.Total.Refresh.Total >= 1, Equals, true)
+	c.Assert(index.Primaries.Store.SizeInBytes > 0, Equals, true)
+	c.Assert(stats.All.Primaries.Docs.Count >= 1, Equals, true)
+
+	stats, err = conn.IndicesStats([]string{indexName}, []string{"docs"}, url.Values{})
+	c.Assert(err, IsNil)
+	c.Assert(stats.Indices[indexName].Primaries.Docs.Count, Equals, int64(1))
+	c.Assert(stats.Indices[indexName].Primaries.Indexing.IndexTotal, Equals, int64(0))
+}
+
+func (s *GoesTestSuite) TestIndicesStatsDecode(c *C) {
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/a,b/_stats/merges,segments,query_cache")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"_shards": {"total": 2, "successful": 2, "failed": 0},
+			"_all": {"total": {"merges": {"total": 4}}},
+			"indices": {
+				"a": {
+					"primaries": {
+						"merges": {"current": 1, "total": 3, "total_time_in_millis": 12},
+						"segments": {"count": 7, "memory_in_bytes": 1024},
+						"query_cache": {"hit_count": 5, "miss_count": 2, "evictions": 1}
+					},
+					"total": {"merges": {"total": 3}}
+				},
+				"b": {"total": {"merges": {"total": 1}}}
+			}
+		}`))
+	})
+	defer ts.Close()
+
+	stats, err := conn.IndicesStats([]string{"a", "b"}, []string{"merges", "segments", "query_cache"}, url.Values{})
+	c.Assert(err, IsNil)
+	c.Assert(stats.Shards.Successful, Equals, uint64(2))
+	c.Assert(stats.All.Total.Merges.Total, Equals, int64(4))
+	c.Assert(stats.Indices, HasLen, 2)
+
+	a := stats.Indices["a"].Primaries
+	c.Assert(a.Merges, Equals, MergesStats{Current: 1, Total: 3, TotalTimeInMillis: 12})
+	c.Assert(a.Segments.Count, Equals, int64(7))
+	c.Assert(a.Segments.MemoryInBytes, Equals, int64(1024))
+	c.Assert(a.QueryCache, Equals, QueryCacheStats{HitCount: 5, MissCount: 2, Evictions: 1})
+	c.Assert(stats.Indices["b"].Total.Merges.Total, Equals, int64(1))
+}
+
+func (s *GoesTestSuite) TestNodesStats(c *C) {
+	conn := NewClient(ESHost, ESPort)
+
+	stats, err := conn.NodesStats([]string{"_local"}, []string{"jvm", "thread_pool"}, url.Values{})
+	c.Assert(err, IsNil)
+	c.Assert(stats.Nodes, HasLen, 1)
+
+	for _, node := range stats.Nodes {
+		c.Assert(node.Name, Not(Equals), "")
+		c.Assert(node.JVM.Mem.HeapUsedInBytes > 0, Equals, true)
+		c.Assert(node.JVM.Mem.HeapMaxInBytes > 0, Equals, true)
+		c.Assert(node.ThreadPool["search"].Threads >= 0, Equals, true)
+		c.Assert(node.FS.Data, IsNil)
+	}
+}
+
+func (s *GoesTestSuite) TestNodesStatsDecode(c *C) {
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/_nodes/stats")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"cluster_name": "goes",
+			"nodes": {
+				"n1": {
+					"name": "node-1",
+					"roles": ["master", "data"],
+					"indices": {"docs": {"count": 10}},
+					"os": {
+						"cpu": {"percent": 12, "load_average": {"1m": 0.5, "5m": 0.25}},
+						"mem": {"total_in_bytes": 100, "free_in_bytes": 40, "used_percent": 60}
+					},
+					"jvm": {
+						"mem": {"heap_used_percent": 42, "pools": {"young": {"used_in_bytes": 8}}},
+						"gc": {"collectors": {"old": {"collection_count": 3, "collection_time_in_millis": 30}}}
+					},
+					"thread_pool": {
+						"write": {"threads": 4, "queue": 200, "active": 4, "rejected": 17}
+					},
+					"fs": {
+						"total": {"total_in_bytes": 1000, "available_in_bytes": 250},
+						"data": [{"path": "/data", "available_in_bytes": 250}]
+					},
+					"breakers": {
+						"parent": {"limit_size_in_bytes": 2048, "limit_size": "2kb", "overhead": 1.0, "tripped": 2}
+					}
+				}
+			}
+		}`))
+	})
+	defer ts.Close()
+
+	stats, err := conn.NodesStats(nil, nil, url.Values{})
+	c.Assert(err, IsNil)
+	c.Assert(stats.ClusterName, Equals, "goes")
+
+	node := stats.Nodes["n1"]
+	c.Assert(node.Name, Equals, "node-1")
+	c.Assert(node.Roles, DeepEquals, []string{"master", "data"})
+	c.Assert(node.Indices.Docs.Count, Equals, int64(10))
+	c.Assert(node.OS.CPU.Percent, Equals, int64(12))
+	c.Assert(node.OS.CPU.LoadAverage["1m"], Equals, 0.5)
+	c.Assert(node.OS.Mem, Equals, MemoryStats{TotalInBytes: 100, FreeInBytes: 40, UsedPercent: 60})
+	c.Assert(node.JVM.Mem.HeapUsedPercent, Equals, int64(42))
+	c.Assert(node.JVM.Mem.Pools["young"].UsedInBytes, Equals, int64(8))
+	c.Assert(node.JVM.GC.Collectors["old"], Equals, JVMGarbageCollectorStats{CollectionCount: 3, CollectionTimeInMillis: 30})
+	c.Assert(node.ThreadPool["write"], Equals, ThreadPoolStats{Threads: 4, Queue: 200, Active: 4, Rejected: 17})
+	c.Assert(node.FS.Total.AvailableInBytes, Equals, int64(250))
+	c.Assert(node.FS.Data, DeepEquals, []FSDataStats{{Path: "/data", AvailableInBytes: 250}})
+	c.Assert(node.Breakers["parent"], Equals, BreakerStats{LimitSizeInBytes: 2048, LimitSize: "2kb", Overhead: 1.0, Tripped: 2})
+}
+
+func (s *GoesTestSuite) TestNodesStatsPath(c *C) {
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/_nodes/n1,n2/stats/os,fs")
+		c.Check(r.URL.Query().Get("level"), Equals, "indices")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"nodes": {}}`))
+	})
+	defer ts.Close()
+
+	stats, err := conn.NodesStats([]string{"n1", "n2"}, []string{"os", "fs"}, url.Values{"level": []string{"indices"}})
+	c.Assert(err, IsNil)
+	c.Assert(stats.Nodes, HasLen, 0)
+}
@@ -40,6 +40,8 @@ type Response struct {
 	ID           string `json:"_id"`
 	Type         string `json:"_type"`
 	Version      int    `json:"_version"`
+	SeqNo        int64  `json:"_seq_no"`
+	PrimaryTerm  int64  `json:"_primary_term"`
 	Found        bool
 	Count        int
 
@@ -53,6 +55,12 @@ type Response struct {
 	Source map[string]interface{} `json:"_source"`
 	Fields map[string]interface{} `json:"fields"`
 
+	// Used by the _mget API
+	Docs []Response `json:"docs,omitempty"`
+
+	// Used by the _msearch API
+	Responses []Response `json:"responses,omitempty"`
+
 	// Used by the _status API
 	Indices map[string]IndexStatus
 
@@ -61,6 +69,12 @@ type Response struct {
 
 	Aggregations map[string]Aggregation `json:"aggregations,omitempty"`
 
+	// Suggestions by suggester name
+	Suggest map[string][]Suggestion `json:"suggest,omitempty"`
+
+	// Used by profiled searches
+	Profile *Profile `json:"profile,omitempty"`
+
 	Raw map[string]interface{}
 }
 
@@ -78,20 +92,152 @@ type Document struct {
 	ID          interface{}
 	BulkCommand string
 	Fields      interface{}
+
+	// Optimistic concurrency control: when IfPrimaryTerm is set, the
+	// operation fails with a conflict if the document was modified since
+	// it had these _seq_no and _primary_term
+	IfSeqNo       int64
+	IfPrimaryTerm int64
+
+	// Pipeline is the ingest pipeline the document goes through when it is
+	// indexed, with Index, Update or the index and create bulk commands
+	Pipeline string
+}
+
+// MGetDoc identifies a document to fetch with the _mget API
+type MGetDoc struct {
+	Index   string `json:"_index,omitempty"`
+	Type    string `json:"_type,omitempty"`
+	ID      string `json:"_id"`
+	Routing string `json:"routing,omitempty"`
+
+	// Source filters the returned _source, it can be a bool, a list of
+	// fields or a map with "includes" and "excludes" keys
+	Source interface{} `json:"_source,omitempty"`
+}
+
+// MSearchQuery holds a single search of a _msearch request
+type MSearchQuery struct {
+	// Header holds the index, type, routing, search_type, etc. of the search
+	Header map[string]interface{}
+
+	// Query holds the body of the search, as it would be given to Search
+	Query interface{}
+}
+
+// BulkByScrollStatus holds the progress of an _update_by_query,
+// _delete_by_query or _reindex operation
+type BulkByScrollStatus struct {
+	Total             uint64  `json:"total"`
+	Updated           uint64  `json:"updated"`
+	Created           uint64  `json:"created"`
+	Deleted           uint64  `json:"deleted"`
+	Batches           uint64  `json:"batches"`
+	VersionConflicts  uint64  `json:"version_conflicts"`
+	Noops             uint64  `json:"noops"`
+	Retries           Retries `json:"retries"`
+	ThrottledMillis   uint64  `json:"throttled_millis"`
+	RequestsPerSecond float64 `json:"requests_per_second"`
+}
+
+// Retries counts the bulk and search requests retried by a bulk by scroll
+// operation. ES 2.x only reports a single counter, which is stored in Bulk.
+type Retries struct {
+	Bulk   uint64 `json:"bulk"`
+	Search uint64 `json:"search"`
+}
+
+// BulkByScrollResponse holds the response of an _update_by_query,
+// _delete_by_query or _reindex operation
+type BulkByScrollResponse struct {
+	BulkByScrollStatus
+
+	// Set instead of everything else when the operation runs in the background
+	Task string `json:"task"`
+
+	Took     uint64            `json:"took"`
+	TimedOut bool              `json:"timed_out"`
+	Failures []json.RawMessage `json:"failures"`
+}
+
+// ReindexBody describes a _reindex operation
+type ReindexBody struct {
+	// Conflicts can be set to "proceed" to ignore version conflicts
+	Conflicts string        `json:"conflicts,omitempty"`
+	Source    ReindexSource `json:"source"`
+	Dest      ReindexDest   `json:"dest"`
+	Script    *Script       `json:"script,omitempty"`
+}
+
+// ReindexSource selects the documents to reindex
+type ReindexSource struct {
+	Index  []string       `json:"index"`
+	Type   []string       `json:"type,omitempty"`
+	Query  interface{}    `json:"query,omitempty"`
+	Size   int            `json:"size,omitempty"`
+	Sort   interface{}    `json:"sort,omitempty"`
+	Source interface{}    `json:"_source,omitempty"`
+	Remote *ReindexRemote `json:"remote,omitempty"`
+}
+
+// ReindexRemote holds the connection to a remote cluster to reindex from. Its
+// host must be whitelisted with reindex.remote.whitelist on the destination.
+type ReindexRemote struct {
+	Host           string `json:"host"`
+	Username       string `json:"username,omitempty"`
+	Password       string `json:"password,omitempty"`
+	SocketTimeout  string `json:"socket_timeout,omitempty"`
+	ConnectTimeout string `json:"connect_timeout,omitempty"`
+}
+
+// ReindexDest describes where reindexed documents are written
+type ReindexDest struct {
+	Index       string `json:"index"`
+	Type        string `json:"type,omitempty"`
+	OpType      string `json:"op_type,omitempty"`
+	VersionType string `json:"version_type,omitempty"`
+	Routing     string `json:"routing,omitempty"`
+	Pipeline    string `json:"pipeline,omitempty"`
+}
+
+// Script holds a script, either inline or stored. ES versions before 5.6 use
+// Inline instead of Source for inline scripts.
+type Script struct {
+	Source string                 `json:"source,omitempty"`
+	Inline string                 `json:"inline,omitempty"`
+	ID     string                 `json:"id,omitempty"`
+	Lang   string                 `json:"lang,omitempty"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// AliasAction holds a single action of an _aliases request
+type AliasAction struct {
+	// One of the AliasAction* constants
+	Action string `json:"-"`
+
+	Index         string      `json:"index"`
+	Alias         string      `json:"alias,omitempty"`
+	Filter        interface{} `json:"filter,omitempty"`
+	Routing       string      `json:"routing,omitempty"`
+	IndexRouting  string      `json:"index_routing,omitempty"`
+	SearchRouting string      `json:"search_routing,omitempty"`
+	IsWriteIndex  *bool       `json:"is_write_index,omitempty"`
 }
 
 // Item holds an item from the "items" field in a _bulk response
 type Item struct {
-	Type    string `json:"_type"`
-	ID      string `json:"_id"`
-	Index   string `json:"_index"`
-	Version int    `json:"_version"`
-	Error   string `json:"error"`
-	Status  uint64 `json:"status"`
+	Type        string `json:"_type"`
+	ID          string `json:"_id"`
+	Index       string `json:"_index"`
+	Version     int    `json:"_version"`
+	SeqNo       int64  `json:"_seq_no"`
+	PrimaryTerm int64  `json:"_primary_term"`
+	Error       string `json:"error"`
+	Status      uint64 `json:"status"`
 }
 
 // All represents the "_all" field when calling the _stats API
-// This is minimal but this is what I only need
+// This is minimal, use IndicesStats for the complete statistics
 type All struct {
 	Indices   map[string]StatIndex   `json:"indices"`
 	Primaries map[string]StatPrimary `json:"primaries"`
@@ -118,18 +264,54 @@ type Shard struct {
 
 // Hit holds a hit returned by a search
 type Hit struct {
-	Index     string                 `json:"_index"`
-	Type      string                 `json:"_type"`
-	ID        string                 `json:"_id"`
-	Score     float64                `json:"_score"`
-	Source    map[string]interface{} `json:"_source"`
-	Highlight map[string]interface{} `json:"highlight"`
-	Fields    map[string]interface{} `json:"fields"`
+	Index       string                 `json:"_index"`
+	Type        string                 `json:"_type"`
+	ID          string                 `json:"_id"`
+	Score       float64                `json:"_score"`
+	Routing     string                 `json:"_routing"`
+	SeqNo       int64                  `json:"_seq_no"`
+	PrimaryTerm int64                  `json:"_primary_term"`
+	Source      map[string]interface{} `json:"_source"`
+	Highlight   map[string][]string    `json:"highlight"`
+	Fields      map[string]interface{} `json:"fields"`
+
+	// Names of the named queries the hit matched
+	MatchedQueries []string `json:"matched_queries"`
+
+	// Sort values of the hit, when the search is sorted. Numbers are
+	// json.Number, to keep integers which do not fit in a float64.
+	Sort []interface{} `json:"sort"`
+
+	// Used by searches with explain
+	Explanation *Explanation `json:"_explanation"`
+
+	// Inner hits by name, for nested and parent-child queries with inner_hits
+	InnerHits map[string]Hits `json:"inner_hits"`
+
+	// Position of the hit in its parent document, for nested inner hits
+	Nested *NestedIdentity `json:"_nested"`
+}
+
+// NestedIdentity locates a nested document: the offset of the object in the
+// field of its parent, which may itself be nested
+type NestedIdentity struct {
+	Field  string          `json:"field"`
+	Offset int             `json:"offset"`
+	Nested *NestedIdentity `json:"_nested"`
+}
+
+// TotalHits holds the number of hits of a search. Since ES 7, it may only be
+// a lower bound of the number of hits, see TrackTotalHits.
+type TotalHits struct {
+	Value uint64 `json:"value"`
+
+	// One of the TotalHits* constants, it is empty when hits are not counted
+	Relation string `json:"relation"`
 }
 
 // Hits holds the hits structure as returned by elasticsearch
 type Hits struct {
-	Total uint64
+	Total TotalHits
 	// max_score may contain the "null" value
 	MaxScore interface{} `json:"max_score"`
 	Hits     []Hit
@@ -141,6 +323,21 @@ type SearchError struct {
 	StatusCode uint64
 }
 
+// HTTPError is returned when a response can not be handled as an elasticsearch
+// response, e.g. a rejected redirect or an error page from a proxy
+type HTTPError struct {
+	StatusCode uint64
+
+	// Content type of the body as sent by the server
+	ContentType string
+
+	// Target of a rejected redirect
+	Location string
+
+	// Body of the response, verbatim
+	Body string
+}
+
 // IndexStatus holds the status for a given index for the _status command
 type IndexStatus struct {
 	// XXX : problem, int will be marshaled to a float64 which seems logical
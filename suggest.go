@@ -0,0 +1,135 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"encoding/json"
+)
+
+const (
+	// SuggesterTerm suggests corrections for each term of the text
+	SuggesterTerm = "term"
+	// SuggesterPhrase suggests corrections for the whole text
+	SuggesterPhrase = "phrase"
+	// SuggesterCompletion suggests the documents whose completion field
+	// starts with a prefix
+	SuggesterCompletion = "completion"
+)
+
+// Suggester holds a suggester of a search, given by name in the "suggest"
+// section of the query. It is encoded with Field, Size and Options in the
+// section of its Type.
+type Suggester struct {
+	// One of the Suggester* constants
+	Type string
+
+	// Text to correct, for the term and phrase suggesters
+	Text string
+
+	// Prefix or Regex to complete, for the completion suggester
+	Prefix string
+	Regex  string
+
+	Field string
+	Size  int
+
+	// Options holds the other parameters of the suggester, such as
+	// "suggest_mode" or "fuzzy"
+	Options map[string]interface{}
+}
+
+// TermSuggester returns a suggester of corrections for each term of text,
+// taken from the terms of field
+func TermSuggester(text string, field string) Suggester {
+	return Suggester{Type: SuggesterTerm, Text: text, Field: field}
+}
+
+// PhraseSuggester returns a suggester of corrections for the whole text,
+// taken from the terms of field
+func PhraseSuggester(text string, field string) Suggester {
+	return Suggester{Type: SuggesterPhrase, Text: text, Field: field}
+}
+
+// CompletionSuggester returns a suggester of the documents whose completion
+// field starts with prefix
+func CompletionSuggester(prefix string, field string) Suggester {
+	return Suggester{Type: SuggesterCompletion, Prefix: prefix, Field: field}
+}
+
+// MarshalJSON encodes the suggester as expected in the "suggest" section of
+// a query
+func (s Suggester) MarshalJSON() ([]byte, error) {
+	params := make(map[string]interface{}, len(s.Options)+2)
+	for name, value := range s.Options {
+		params[name] = value
+	}
+	if s.Field != "" {
+		params["field"] = s.Field
+	}
+	if s.Size > 0 {
+		params["size"] = s.Size
+	}
+
+	suggester := map[string]interface{}{s.Type: params}
+	if s.Text != "" {
+		suggester["text"] = s.Text
+	}
+	if s.Prefix != "" {
+		suggester["prefix"] = s.Prefix
+	}
+	if s.Regex != "" {
+		suggester["regex"] = s.Regex
+	}
+
+	return json.Marshal(suggester)
+}
+
+// Suggestion holds the suggestions for a part of the text of a suggester:
+// each of its terms for the term suggester, the whole text otherwise
+type Suggestion struct {
+	Text    string             `json:"text"`
+	Offset  int                `json:"offset"`
+	Length  int                `json:"length"`
+	Options []SuggestionOption `json:"options"`
+}
+
+// SuggestionOption holds a suggested text and its score. Freq is only set by
+// the term suggester, Highlighted and CollateMatch by the phrase suggester.
+// The completion suggester returns the suggested document since ES 5, and
+// its payload before.
+type SuggestionOption struct {
+	Text         string  `json:"text"`
+	Score        float64 `json:"score"`
+	Freq         int64   `json:"freq"`
+	Highlighted  string  `json:"highlighted"`
+	CollateMatch *bool   `json:"collate_match"`
+
+	Index    string                 `json:"_index"`
+	Type     string                 `json:"_type"`
+	ID       string                 `json:"_id"`
+	Source   map[string]interface{} `json:"_source"`
+	Contexts map[string][]string    `json:"contexts"`
+	Payload  interface{}            `json:"payload"`
+}
+
+// UnmarshalJSON decodes a suggestion option, reading the score of completion
+// suggestions from _score
+func (o *SuggestionOption) UnmarshalJSON(data []byte) error {
+	type option SuggestionOption
+	var raw struct {
+		option
+		DocScore *float64 `json:"_score"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*o = SuggestionOption(raw.option)
+	if raw.DocScore != nil {
+		o.Score = *raw.DocScore
+	}
+
+	return nil
+}
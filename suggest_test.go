@@ -0,0 +1,156 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	. "github.com/go-check/check"
+)
+
+func (s *GoesTestSuite) TestSuggest(c *C) {
+	indexName := "testsuggest"
+	docType := "song"
+
+	conn := NewClient(ESHost, ESPort)
+	if supported, _ := conn.versionAtLeast("5"); !supported {
+		c.Skip("Completion suggestions with _source require ES 5, skipping this test")
+	}
+	mappingType := docType
+	if supported, _ := conn.versionAtLeast("7"); supported {
+		docType = "_doc"
+		mappingType = ""
+	}
+	conn.DeleteIndex(indexName)
+
+	_, err := conn.CreateIndex(indexName, map[string]interface{}{})
+	c.Assert(err, IsNil)
+	defer conn.DeleteIndex(indexName)
+
+	_, err = conn.PutMapping(mappingType, map[string]interface{}{
+		"properties": map[string]interface{}{
+			"title":   map[string]interface{}{"type": "text"},
+			"suggest": map[string]interface{}{"type": "completion"},
+		},
+	}, []string{indexName})
+	c.Assert(err, IsNil)
+
+	_, err = conn.BulkSend([]Document{
+		{Index: indexName, Type: docType, ID: "1", BulkCommand: BulkCommandIndex, Fields: map[string]interface{}{
+			"title": "nevermind", "suggest": "Nevermind",
+		}},
+		{Index: indexName, Type: docType, ID: "2", BulkCommand: BulkCommandIndex, Fields: map[string]interface{}{
+			"title": "nevertheless", "suggest": "Nevertheless",
+		}},
+	})
+	c.Assert(err, IsNil)
+	_, err = conn.RefreshIndex(indexName)
+	c.Assert(err, IsNil)
+
+	completion := CompletionSuggester("never", "suggest")
+	completion.Size = 1
+	query := map[string]interface{}{
+		"size": 0,
+		"suggest": map[string]Suggester{
+			"titles":     completion,
+			"correction": TermSuggester("nevermnd", "title"),
+			"phrase":     PhraseSuggester("nevermnd", "title"),
+		},
+	}
+
+	resp, err := conn.Search(query, []string{indexName}, nil, url.Values{})
+	c.Assert(err, IsNil)
+	c.Assert(resp.Hits.Hits, HasLen, 0)
+
+	titles := resp.Suggest["titles"]
+	c.Assert(titles, HasLen, 1)
+	c.Assert(titles[0].Text, Equals, "never")
+	c.Assert(titles[0].Options, HasLen, 1)
+	c.Assert(titles[0].Options[0].ID, Equals, "1")
+	c.Assert(titles[0].Options[0].Text, Equals, "Nevermind")
+	c.Assert(titles[0].Options[0].Score > 0, Equals, true)
+	c.Assert(titles[0].Options[0].Source["title"], Equals, "nevermind")
+
+	correction := resp.Suggest["correction"]
+	c.Assert(correction, HasLen, 1)
+	c.Assert(correction[0].Text, Equals, "nevermnd")
+	c.Assert(correction[0].Options[0].Text, Equals, "nevermind")
+	c.Assert(correction[0].Options[0].Freq, Equals, int64(1))
+
+	phrase := resp.Suggest["phrase"]
+	c.Assert(phrase, HasLen, 1)
+	c.Assert(phrase[0].Options[0].Text, Equals, "nevermind")
+}
+
+func (s *GoesTestSuite) TestSuggesterJSON(c *C) {
+	completion := CompletionSuggester("nev", "suggest")
+	completion.Size = 3
+	completion.Options = map[string]interface{}{"fuzzy": map[string]interface{}{"fuzziness": 1}}
+
+	term := TermSuggester("nevermnd", "title")
+	term.Options = map[string]interface{}{"suggest_mode": "always"}
+
+	data, err := json.Marshal(map[string]Suggester{
+		"completion": completion,
+		"term":       term,
+		"phrase":     PhraseSuggester("nevermnd", "title"),
+		"regex":      {Type: SuggesterCompletion, Regex: "n[ae]v", Field: "suggest"},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, `{`+
+		`"completion":{"completion":{"field":"suggest","fuzzy":{"fuzziness":1},"size":3},"prefix":"nev"},`+
+		`"phrase":{"phrase":{"field":"title"},"text":"nevermnd"},`+
+		`"regex":{"completion":{"field":"suggest"},"regex":"n[ae]v"},`+
+		`"term":{"term":{"field":"title","suggest_mode":"always"},"text":"nevermnd"}}`)
+}
+
+func (s *GoesTestSuite) TestSuggestResponse(c *C) {
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"hits": {"total": 0, "hits": []}, "suggest": {
+			"term": [{"text": "nevermnd", "offset": 0, "length": 8, "options": [
+				{"text": "nevermind", "score": 0.875, "freq": 4}
+			]}],
+			"phrase": [{"text": "nevermnd", "offset": 0, "length": 8, "options": [
+				{"text": "nevermind", "highlighted": "<em>nevermind</em>", "score": 0.25, "collate_match": true}
+			]}],
+			"completion": [{"text": "nev", "offset": 0, "length": 3, "options": [
+				{"text": "Nevermind", "_index": "music", "_type": "_doc", "_id": "1", "_score": 2.0,
+				 "_source": {"title": "nevermind"}, "contexts": {"genre": ["grunge"]}}
+			]}],
+			"legacy": [{"text": "nev", "offset": 0, "length": 3, "options": [
+				{"text": "Nevermind", "score": 1.0, "payload": {"id": 1}}
+			]}]
+		}}`))
+	})
+	defer ts.Close()
+
+	resp, err := conn.Search(map[string]interface{}{}, []string{"music"}, nil, url.Values{})
+	c.Assert(err, IsNil)
+
+	collateMatch := true
+	c.Assert(resp.Suggest, DeepEquals, map[string][]Suggestion{
+		"term": {{Text: "nevermnd", Length: 8, Options: []SuggestionOption{
+			{Text: "nevermind", Score: 0.875, Freq: 4},
+		}}},
+		"phrase": {{Text: "nevermnd", Length: 8, Options: []SuggestionOption{
+			{Text: "nevermind", Highlighted: "<em>nevermind</em>", Score: 0.25, CollateMatch: &collateMatch},
+		}}},
+		"completion": {{Text: "nev", Length: 3, Options: []SuggestionOption{{
+			Text:     "Nevermind",
+			Index:    "music",
+			Type:     "_doc",
+			ID:       "1",
+			Score:    2.0,
+			Source:   map[string]interface{}{"title": "nevermind"},
+			Contexts: map[string][]string{"genre": {"grunge"}},
+		}}}},
+		"legacy": {{Text: "nev", Length: 3, Options: []SuggestionOption{
+			{Text: "Nevermind", Score: 1.0, Payload: map[string]interface{}{"id": 1.0}},
+		}}},
+	})
+}
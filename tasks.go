@@ -0,0 +1,134 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// ErrWaitTimeout is returned when an operation waited on did not complete in
+// time, it may still be running
+var ErrWaitTimeout = errors.New("Timed out waiting for the operation to complete")
+
+// TaskInfo describes a task running on a node of the cluster
+type TaskInfo struct {
+	Node               string `json:"node"`
+	ID                 int64  `json:"id"`
+	Type               string `json:"type"`
+	Action             string `json:"action"`
+	Description        string `json:"description"`
+	StartTimeInMillis  int64  `json:"start_time_in_millis"`
+	RunningTimeInNanos int64  `json:"running_time_in_nanos"`
+	Cancellable        bool   `json:"cancellable"`
+	ParentTaskID       string `json:"parent_task_id"`
+
+	// Only filled for _update_by_query, _delete_by_query and _reindex tasks
+	Status BulkByScrollStatus `json:"status"`
+}
+
+// TaskResult holds the state of a single task as returned by GetTask
+type TaskResult struct {
+	Completed bool     `json:"completed"`
+	Task      TaskInfo `json:"task"`
+
+	// Once completed, the task holds either a response or an error
+	Response *BulkByScrollResponse `json:"response"`
+	Error    json.RawMessage       `json:"error"`
+}
+
+// TaskNode holds the tasks running on a node
+type TaskNode struct {
+	Name             string              `json:"name"`
+	TransportAddress string              `json:"transport_address"`
+	Host             string              `json:"host"`
+	IP               string              `json:"ip"`
+	Tasks            map[string]TaskInfo `json:"tasks"`
+}
+
+// TaskList holds the tasks returned by ListTasks and CancelTask, grouped by node
+type TaskList struct {
+	Nodes        map[string]TaskNode `json:"nodes"`
+	NodeFailures []json.RawMessage   `json:"node_failures"`
+}
+
+// GetTask fetches the state of a task by its id, as returned by the APIs run
+// with wait_for_completion=false
+func (c *Client) GetTask(taskID string) (*TaskResult, error) {
+	r := Request{
+		Method: "GET",
+		API:    "_tasks/" + taskID,
+	}
+
+	result := &TaskResult{}
+	return result, c.doInto(&r, result)
+}
+
+// ListTasks lists the tasks running in the cluster. extraArgs can be used to
+// filter them, for example with actions=*reindex or nodes, and to get their
+// status with detailed=true.
+func (c *Client) ListTasks(extraArgs url.Values) (*TaskList, error) {
+	r := Request{
+		Method:    "GET",
+		API:       "_tasks",
+		ExtraArgs: extraArgs,
+	}
+
+	list := &TaskList{}
+	return list, c.doInto(&r, list)
+}
+
+// CancelTask cancels a task by its id and returns the tasks being cancelled
+func (c *Client) CancelTask(taskID string) (*TaskList, error) {
+	r := Request{
+		Method: "POST",
+		API:    "_tasks/" + taskID + "/_cancel",
+	}
+
+	list := &TaskList{}
+	return list, c.doInto(&r, list)
+}
+
+// WaitForTask polls the task every interval until it completes, or returns
+// ErrWaitTimeout once timeout passed. progress, if not nil, is called with
+// the task after each poll. An error is returned if the task completed with
+// an error, or with failures or timed out for a bulk by scroll task, along
+// with the result to inspect.
+func (c *Client) WaitForTask(taskID string, interval time.Duration, timeout time.Duration, progress func(TaskInfo)) (*TaskResult, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		result, err := c.GetTask(taskID)
+		if err != nil {
+			return result, err
+		}
+
+		if progress != nil {
+			progress(result.Task)
+		}
+
+		if result.Completed {
+			if len(result.Error) > 0 {
+				return result, &SearchError{Msg: string(result.Error)}
+			}
+			if resp := result.Response; resp != nil {
+				if len(resp.Failures) > 0 {
+					return result, fmt.Errorf("Task %s failed for %d documents: %s", taskID, len(resp.Failures), resp.Failures[0])
+				}
+				if resp.TimedOut {
+					return result, fmt.Errorf("Task %s timed out", taskID)
+				}
+			}
+			return result, nil
+		}
+
+		if !time.Now().Before(deadline) {
+			return result, ErrWaitTimeout
+		}
+		time.Sleep(interval)
+	}
+}
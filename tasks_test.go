@@ -0,0 +1,170 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	. "github.com/go-check/check"
+)
+
+func (s *GoesTestSuite) TestUpdateByQueryAndReindex(c *C) {
+	indexName := "testupdatebyquery"
+	destName := "testreindex"
+	docType := "tweet"
+
+	conn := NewClient(ESHost, ESPort)
+	if version, _ := conn.Version(); version < "5" {
+		c.Skip("Task tracking of _update_by_query and _reindex requires ES 5.x, skipping this test")
+	}
+	conn.DeleteIndex(indexName)
+	conn.DeleteIndex(destName)
+
+	_, err := conn.CreateIndex(indexName, map[string]interface{}{})
+	c.Assert(err, IsNil)
+	defer conn.DeleteIndex(indexName)
+	defer conn.DeleteIndex(destName)
+
+	for _, id := range []string{"1", "2", "3"} {
+		d := Document{
+			Index:  indexName,
+			Type:   docType,
+			ID:     id,
+			Fields: map[string]interface{}{"user": "foo", "counter": 1},
+		}
+		_, err = conn.Index(d, url.Values{})
+		c.Assert(err, IsNil)
+	}
+
+	_, err = conn.RefreshIndex(indexName)
+	c.Assert(err, IsNil)
+
+	script := &Script{
+		Inline: "ctx._source.counter += params.count",
+		Lang:   "painless",
+		Params: map[string]interface{}{"count": 1},
+	}
+	if version, _ := conn.Version(); version >= "5.6" {
+		script.Source, script.Inline = script.Inline, ""
+	}
+
+	response, err := conn.UpdateByQuery(map[string]interface{}{
+		"query":  map[string]interface{}{"match_all": map[string]interface{}{}},
+		"script": script,
+	}, []string{indexName}, nil, url.Values{"wait_for_completion": []string{"false"}})
+	c.Assert(err, IsNil)
+	c.Assert(response.Task, Not(Equals), "")
+
+	calls := 0
+	result, err := conn.WaitForTask(response.Task, 100*time.Millisecond, time.Minute, func(task TaskInfo) {
+		calls++
+		c.Assert(task.Action, Equals, "indices:data/write/update/byquery")
+	})
+	c.Assert(err, IsNil)
+	c.Assert(calls > 0, Equals, true)
+	c.Assert(result.Completed, Equals, true)
+	c.Assert(result.Response.Updated, Equals, uint64(3))
+
+	response, err = conn.Reindex(ReindexBody{
+		Source: ReindexSource{Index: []string{indexName}},
+		Dest:   ReindexDest{Index: destName},
+	}, url.Values{"refresh": []string{"true"}})
+	c.Assert(err, IsNil)
+	c.Assert(response.Task, Equals, "")
+	c.Assert(response.Created, Equals, uint64(3))
+
+	doc, err := conn.Get(destName, docType, "1", url.Values{})
+	c.Assert(err, IsNil)
+	c.Assert(doc.Source["counter"], Equals, 2.0)
+}
+
+func (s *GoesTestSuite) TestListTasks(c *C) {
+	conn := NewClient(ESHost, ESPort)
+	if version, _ := conn.Version(); version < "2.3" {
+		c.Skip("The task management API requires ES 2.3, skipping this test")
+	}
+
+	list, err := conn.ListTasks(url.Values{"actions": []string{"cluster:monitor/tasks/lists*"}})
+	c.Assert(err, IsNil)
+	c.Assert(len(list.Nodes) > 0, Equals, true)
+
+	_, err = conn.CancelTask("unknown:1")
+	c.Assert(err, NotNil)
+}
+
+func (s *GoesTestSuite) TestWaitForTask(c *C) {
+	polls := 0
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/_tasks/node:42")
+		w.Header().Set("Content-Type", "application/json")
+
+		polls++
+		switch polls {
+		case 1:
+			w.Write([]byte(`{"completed": false, "task": {"node": "node", "id": 42, "action": "indices:data/write/reindex",
+				"status": {"total": 10, "created": 4, "retries": {"bulk": 1, "search": 0}}}}`))
+		case 2:
+			w.Write([]byte(`{"completed": true, "task": {"node": "node", "id": 42, "action": "indices:data/write/reindex",
+				"status": {"total": 10, "created": 10, "retries": {"bulk": 1, "search": 0}}},
+				"response": {"took": 12, "total": 10, "created": 10, "retries": 1, "failures": []}}`))
+		default:
+			c.Errorf("task polled after its completion")
+		}
+	})
+	defer ts.Close()
+
+	created := []uint64{}
+	result, err := conn.WaitForTask("node:42", time.Millisecond, time.Second, func(task TaskInfo) {
+		c.Check(task.ID, Equals, int64(42))
+		created = append(created, task.Status.Created)
+	})
+	c.Assert(err, IsNil)
+	c.Assert(created, DeepEquals, []uint64{4, 10})
+	c.Assert(result.Completed, Equals, true)
+	c.Assert(result.Task.Status.Retries, Equals, Retries{Bulk: 1})
+	c.Assert(result.Response.Took, Equals, uint64(12))
+	c.Assert(result.Response.Retries, Equals, Retries{Bulk: 1})
+}
+
+func (s *GoesTestSuite) TestWaitForTaskError(c *C) {
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"completed": true, "task": {"node": "node", "id": 1}, "error": {"type": "search_phase_execution_exception"}}`))
+	})
+	defer ts.Close()
+
+	_, err := conn.WaitForTask("node:1", time.Millisecond, time.Second, nil)
+	c.Assert(err, ErrorMatches, `.*search_phase_execution_exception.*`)
+}
+
+func (s *GoesTestSuite) TestWaitForTaskFailures(c *C) {
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"completed": true, "task": {"node": "node", "id": 1},
+			"response": {"total": 2, "updated": 1, "failures": [{"id": "2", "status": 409}]}}`))
+	})
+	defer ts.Close()
+
+	result, err := conn.WaitForTask("node:1", time.Millisecond, time.Second, nil)
+	c.Assert(err, ErrorMatches, `Task node:1 failed for 1 documents: {"id": "2", "status": 409}`)
+	c.Assert(result.Response.Updated, Equals, uint64(1))
+}
+
+func (s *GoesTestSuite) TestWaitForTaskTimeout(c *C) {
+	polls := 0
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"completed": false, "task": {"node": "node", "id": 1}}`))
+	})
+	defer ts.Close()
+
+	result, err := conn.WaitForTask("node:1", 10*time.Millisecond, 25*time.Millisecond, nil)
+	c.Assert(err, Equals, ErrWaitTimeout)
+	c.Assert(result.Completed, Equals, false)
+	c.Assert(polls >= 2, Equals, true)
+}
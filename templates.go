@@ -0,0 +1,313 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrComposableTemplates is returned by the component template APIs, and when
+// putting a template composed of component templates, if the server does not
+// support composable templates (ES < 7.8)
+var ErrComposableTemplates = errors.New("Composable templates are not supported before ES 7.8")
+
+// TemplateBody holds the settings, mappings and aliases applied to the
+// indexes matching a template
+type TemplateBody struct {
+	Settings map[string]interface{} `json:"settings,omitempty"`
+	Mappings map[string]interface{} `json:"mappings,omitempty"`
+	Aliases  map[string]interface{} `json:"aliases,omitempty"`
+}
+
+// IndexTemplate holds a composable index template (_index_template)
+type IndexTemplate struct {
+	IndexPatterns []string               `json:"index_patterns"`
+	Template      *TemplateBody          `json:"template,omitempty"`
+	ComposedOf    []string               `json:"composed_of,omitempty"`
+	Priority      int                    `json:"priority,omitempty"`
+	Version       int                    `json:"version,omitempty"`
+	Meta          map[string]interface{} `json:"_meta,omitempty"`
+}
+
+// ComponentTemplate holds a building block of composable index templates
+// (_component_template)
+type ComponentTemplate struct {
+	Template TemplateBody           `json:"template"`
+	Version  int                    `json:"version,omitempty"`
+	Meta     map[string]interface{} `json:"_meta,omitempty"`
+}
+
+// LegacyTemplate holds an index template as handled by the _template API
+type LegacyTemplate struct {
+	Order         int      `json:"order"`
+	Version       int      `json:"version,omitempty"`
+	IndexPatterns []string `json:"index_patterns,omitempty"`
+
+	// Template holds the index pattern before ES 6.0
+	Template string `json:"template,omitempty"`
+
+	Settings map[string]interface{} `json:"settings,omitempty"`
+	Mappings map[string]interface{} `json:"mappings,omitempty"`
+	Aliases  map[string]interface{} `json:"aliases,omitempty"`
+}
+
+// SimulatedIndex holds the configuration an index would get from the
+// templates matching its name
+type SimulatedIndex struct {
+	Template    TemplateBody `json:"template"`
+	Overlapping []struct {
+		Name          string   `json:"name"`
+		IndexPatterns []string `json:"index_patterns"`
+	} `json:"overlapping"`
+}
+
+// PutTemplate creates or replaces a legacy index template
+func (c *Client) PutTemplate(name string, template LegacyTemplate) (*Response, error) {
+	r := Request{
+		Query:  template,
+		Method: "PUT",
+		API:    "_template/" + name,
+	}
+
+	return c.Do(&r)
+}
+
+// GetTemplate returns the legacy index templates by name, all of them if no
+// name is given. Names may contain wildcards.
+func (c *Client) GetTemplate(names []string) (map[string]LegacyTemplate, error) {
+	r := Request{
+		Method: "GET",
+		API:    namedAPI("_template", names),
+	}
+
+	templates := map[string]LegacyTemplate{}
+	return templates, c.doInto(&r, &templates)
+}
+
+// DeleteTemplate deletes a legacy index template
+func (c *Client) DeleteTemplate(name string) (*Response, error) {
+	r := Request{
+		Method: "DELETE",
+		API:    "_template/" + name,
+	}
+
+	return c.Do(&r)
+}
+
+// PutIndexTemplate creates or replaces a composable index template. Before
+// ES 7.8 it is stored as a legacy template instead, using Priority as its
+// order, which fails if it is composed of component templates.
+func (c *Client) PutIndexTemplate(name string, template IndexTemplate) (*Response, error) {
+	composable, err := c.versionAtLeast("7.8")
+	if err != nil {
+		return nil, err
+	}
+	if composable {
+		r := Request{
+			Query:  template,
+			Method: "PUT",
+			API:    "_index_template/" + name,
+		}
+
+		return c.Do(&r)
+	}
+
+	if len(template.ComposedOf) > 0 {
+		return nil, ErrComposableTemplates
+	}
+
+	legacy := LegacyTemplate{
+		Order:         template.Priority,
+		Version:       template.Version,
+		IndexPatterns: template.IndexPatterns,
+	}
+	if template.Template != nil {
+		legacy.Settings = template.Template.Settings
+		legacy.Mappings = template.Template.Mappings
+		legacy.Aliases = template.Template.Aliases
+	}
+	patterns, err := c.versionAtLeast("6")
+	if err != nil {
+		return nil, err
+	}
+	if !patterns {
+		if len(template.IndexPatterns) > 1 {
+			return nil, errors.New("Templates only support one index pattern before ES 6.0")
+		}
+		legacy.Template = strings.Join(template.IndexPatterns, "")
+		legacy.IndexPatterns = nil
+	}
+
+	return c.PutTemplate(name, legacy)
+}
+
+// GetIndexTemplate returns the composable index templates by name, all of them
+// if no name is given. Before ES 7.8 the legacy templates are returned instead.
+func (c *Client) GetIndexTemplate(names []string) (map[string]IndexTemplate, error) {
+	composable, err := c.versionAtLeast("7.8")
+	if err != nil {
+		return nil, err
+	}
+
+	templates := map[string]IndexTemplate{}
+
+	if !composable {
+		legacy, err := c.GetTemplate(names)
+		if err != nil {
+			return nil, err
+		}
+		for name, t := range legacy {
+			patterns := t.IndexPatterns
+			if t.Template != "" {
+				patterns = []string{t.Template}
+			}
+			templates[name] = IndexTemplate{
+				IndexPatterns: patterns,
+				Priority:      t.Order,
+				Version:       t.Version,
+				Template: &TemplateBody{
+					Settings: t.Settings,
+					Mappings: t.Mappings,
+					Aliases:  t.Aliases,
+				},
+			}
+		}
+		return templates, nil
+	}
+
+	r := Request{
+		Method: "GET",
+		API:    namedAPI("_index_template", names),
+	}
+
+	var resp struct {
+		IndexTemplates []struct {
+			Name          string        `json:"name"`
+			IndexTemplate IndexTemplate `json:"index_template"`
+		} `json:"index_templates"`
+	}
+	if err := c.doInto(&r, &resp); err != nil {
+		return nil, err
+	}
+
+	for _, t := range resp.IndexTemplates {
+		templates[t.Name] = t.IndexTemplate
+	}
+
+	return templates, nil
+}
+
+// DeleteIndexTemplate deletes a composable index template, or a legacy one
+// before ES 7.8
+func (c *Client) DeleteIndexTemplate(name string) (*Response, error) {
+	composable, err := c.versionAtLeast("7.8")
+	if err != nil {
+		return nil, err
+	}
+	if !composable {
+		return c.DeleteTemplate(name)
+	}
+
+	r := Request{
+		Method: "DELETE",
+		API:    "_index_template/" + name,
+	}
+
+	return c.Do(&r)
+}
+
+// PutComponentTemplate creates or replaces a component template
+func (c *Client) PutComponentTemplate(name string, template ComponentTemplate) (*Response, error) {
+	if composable, err := c.versionAtLeast("7.8"); err != nil {
+		return nil, err
+	} else if !composable {
+		return nil, ErrComposableTemplates
+	}
+
+	r := Request{
+		Query:  template,
+		Method: "PUT",
+		API:    "_component_template/" + name,
+	}
+
+	return c.Do(&r)
+}
+
+// GetComponentTemplate returns the component templates by name, all of them if
+// no name is given
+func (c *Client) GetComponentTemplate(names []string) (map[string]ComponentTemplate, error) {
+	if composable, err := c.versionAtLeast("7.8"); err != nil {
+		return nil, err
+	} else if !composable {
+		return nil, ErrComposableTemplates
+	}
+
+	r := Request{
+		Method: "GET",
+		API:    namedAPI("_component_template", names),
+	}
+
+	var resp struct {
+		ComponentTemplates []struct {
+			Name              string            `json:"name"`
+			ComponentTemplate ComponentTemplate `json:"component_template"`
+		} `json:"component_templates"`
+	}
+	if err := c.doInto(&r, &resp); err != nil {
+		return nil, err
+	}
+
+	templates := make(map[string]ComponentTemplate, len(resp.ComponentTemplates))
+	for _, t := range resp.ComponentTemplates {
+		templates[t.Name] = t.ComponentTemplate
+	}
+
+	return templates, nil
+}
+
+// DeleteComponentTemplate deletes a component template
+func (c *Client) DeleteComponentTemplate(name string) (*Response, error) {
+	if composable, err := c.versionAtLeast("7.8"); err != nil {
+		return nil, err
+	} else if !composable {
+		return nil, ErrComposableTemplates
+	}
+
+	r := Request{
+		Method: "DELETE",
+		API:    "_component_template/" + name,
+	}
+
+	return c.Do(&r)
+}
+
+// SimulateIndex returns the settings, mappings and aliases an index named
+// indexName would get from the existing templates, without creating it.
+// It requires ES 7.9.
+func (c *Client) SimulateIndex(indexName string) (*SimulatedIndex, error) {
+	if supported, err := c.versionAtLeast("7.9"); err != nil {
+		return nil, err
+	} else if !supported {
+		return nil, errors.New("Simulating an index is not supported before ES 7.9")
+	}
+
+	r := Request{
+		Method: "POST",
+		API:    "_index_template/_simulate_index/" + indexName,
+	}
+
+	simulated := &SimulatedIndex{}
+	return simulated, c.doInto(&r, simulated)
+}
+
+// namedAPI returns the endpoint of an API for a list of names, the endpoint
+// itself selecting everything if the list is empty
+func namedAPI(api string, names []string) string {
+	if len(names) == 0 {
+		return api
+	}
+	return api + "/" + strings.Join(names, ",")
+}
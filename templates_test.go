@@ -0,0 +1,151 @@
+// Copyright 2013 Belogik. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goes
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	. "github.com/go-check/check"
+)
+
+func (s *GoesTestSuite) TestCompareVersions(c *C) {
+	c.Assert(compareVersions("7.10.2", "7.8"), Equals, 1)
+	c.Assert(compareVersions("7.8.0", "7.8"), Equals, 0)
+	c.Assert(compareVersions("7.7.1", "7.8"), Equals, -1)
+	c.Assert(compareVersions("6.0.0-alpha1", "6"), Equals, 0)
+	c.Assert(compareVersions("1.7.5", "2"), Equals, -1)
+}
+
+func (s *GoesTestSuite) TestIndexTemplate(c *C) {
+	templateName := "testindextemplate"
+	indexName := "testindextemplate-2017.01"
+
+	conn := NewClient(ESHost, ESPort)
+	conn.DeleteIndexTemplate(templateName)
+	conn.DeleteIndex(indexName)
+
+	_, err := conn.PutIndexTemplate(templateName, IndexTemplate{
+		IndexPatterns: []string{"testindextemplate-*"},
+		Priority:      10,
+		Template: &TemplateBody{
+			Settings: map[string]interface{}{
+				"index.number_of_replicas": 0,
+			},
+		},
+	})
+	c.Assert(err, IsNil)
+	defer conn.DeleteIndexTemplate(templateName)
+
+	templates, err := conn.GetIndexTemplate([]string{templateName})
+	c.Assert(err, IsNil)
+	c.Assert(templates[templateName].IndexPatterns, DeepEquals, []string{"testindextemplate-*"})
+	c.Assert(templates[templateName].Priority, Equals, 10)
+
+	_, err = conn.CreateIndex(indexName, nil)
+	c.Assert(err, IsNil)
+	defer conn.DeleteIndex(indexName)
+
+	response, err := conn.Do(&Request{
+		IndexList: []string{indexName},
+		Method:    "GET",
+		API:       "_settings",
+	})
+	c.Assert(err, IsNil)
+	settings := response.Raw[indexName].(map[string]interface{})["settings"].(map[string]interface{})
+	c.Assert(settings["index"].(map[string]interface{})["number_of_replicas"], Equals, "0")
+
+	_, err = conn.DeleteIndexTemplate(templateName)
+	c.Assert(err, IsNil)
+
+	_, err = conn.GetIndexTemplate([]string{templateName})
+	c.Assert(err, NotNil)
+}
+
+func (s *GoesTestSuite) TestComponentTemplate(c *C) {
+	componentName := "testcomponenttemplate"
+	templateName := "testcomposedtemplate"
+
+	conn := NewClient(ESHost, ESPort)
+	if composable, _ := conn.versionAtLeast("7.8"); !composable {
+		_, err := conn.PutComponentTemplate(componentName, ComponentTemplate{})
+		c.Assert(err, Equals, ErrComposableTemplates)
+		c.Skip("Composable templates are not supported, skipping this test")
+	}
+
+	_, err := conn.PutComponentTemplate(componentName, ComponentTemplate{
+		Template: TemplateBody{
+			Mappings: map[string]interface{}{
+				"properties": map[string]interface{}{
+					"@timestamp": map[string]interface{}{"type": "date"},
+				},
+			},
+		},
+	})
+	c.Assert(err, IsNil)
+	defer conn.DeleteComponentTemplate(componentName)
+
+	components, err := conn.GetComponentTemplate([]string{componentName})
+	c.Assert(err, IsNil)
+	c.Assert(components[componentName].Template.Mappings, NotNil)
+
+	_, err = conn.PutIndexTemplate(templateName, IndexTemplate{
+		IndexPatterns: []string{"testcomposed-*"},
+		ComposedOf:    []string{componentName},
+		Template: &TemplateBody{
+			Settings: map[string]interface{}{"number_of_shards": 2},
+		},
+	})
+	c.Assert(err, IsNil)
+	defer conn.DeleteIndexTemplate(templateName)
+
+	if supported, _ := conn.versionAtLeast("7.9"); !supported {
+		return
+	}
+
+	simulated, err := conn.SimulateIndex("testcomposed-1")
+	c.Assert(err, IsNil)
+	c.Assert(simulated.Template.Settings["index"].(map[string]interface{})["number_of_shards"], Equals, "2")
+	c.Assert(simulated.Template.Mappings["properties"], NotNil)
+}
+
+func (s *GoesTestSuite) TestPutIndexTemplateLegacy(c *C) {
+	var body map[string]interface{}
+	ts, conn := newTestServer(c, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/" {
+			w.Write([]byte(`{"version": {"number": "5.2.0"}}`))
+			return
+		}
+
+		c.Check(r.URL.Path, Equals, "/_template/legacy")
+		data, err := ioutil.ReadAll(r.Body)
+		c.Check(err, IsNil)
+		c.Check(json.Unmarshal(data, &body), IsNil)
+		w.Write([]byte(`{"acknowledged": true}`))
+	})
+	defer ts.Close()
+
+	_, err := conn.PutIndexTemplate("legacy", IndexTemplate{
+		IndexPatterns: []string{"logs-*"},
+		Priority:      3,
+		Template: &TemplateBody{
+			Settings: map[string]interface{}{"number_of_shards": 1},
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(body, DeepEquals, map[string]interface{}{
+		"order":    3.0,
+		"template": "logs-*",
+		"settings": map[string]interface{}{"number_of_shards": 1.0},
+	})
+
+	_, err = conn.PutIndexTemplate("legacy", IndexTemplate{
+		IndexPatterns: []string{"logs-*"},
+		ComposedOf:    []string{"base"},
+	})
+	c.Assert(err, Equals, ErrComposableTemplates)
+}